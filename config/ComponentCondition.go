@@ -0,0 +1,239 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/convert"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// EvaluateCondition evaluates a condition of a component against configuration parameters.
+//
+//	Condition syntax:
+//		- NAME is true when the parameter is set and its value is not false
+//		- NAME == value, NAME != value compare the parameter with a value
+//		- !expr negates the expression
+//		- expr && expr, expr || expr combine expressions
+//		- ( expr ) groups expressions
+//		- true, false are boolean constants
+//	Values can be quoted with single or double quotes.
+//
+//	Example:
+//		ENV == 'prod' && !DISABLE_METRICS
+//		(ENV == dev || ENV == test) && MONGO_ENABLED
+//
+//	Parameters:
+//		- condition string a condition to evaluate. Empty condition is always true.
+//		- parameters *config.ConfigParams values of parameters referenced by the condition.
+//	Returns: bool, error the condition result and ConfigError when the condition is invalid.
+func EvaluateCondition(condition string, parameters *config.ConfigParams) (bool, error) {
+	if strings.TrimSpace(condition) == "" {
+		return true, nil
+	}
+	if parameters == nil {
+		parameters = config.NewEmptyConfigParams()
+	}
+
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return false, err
+	}
+
+	parser := &conditionParser{
+		condition:  condition,
+		tokens:     tokens,
+		parameters: parameters,
+	}
+
+	result, err := parser.parseOr()
+	if err == nil && parser.position < len(tokens) {
+		err = parser.newError("unexpected '" + tokens[parser.position].value + "'")
+	}
+	if err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+type conditionTokenKind int
+
+const (
+	conditionOperator conditionTokenKind = iota
+	conditionWord
+	conditionString
+)
+
+type conditionToken struct {
+	kind  conditionTokenKind
+	value string
+}
+
+func tokenizeCondition(condition string) ([]conditionToken, error) {
+	tokens := make([]conditionToken, 0)
+	runes := []rune(condition)
+
+	for index := 0; index < len(runes); {
+		ch := runes[index]
+		switch {
+		case unicode.IsSpace(ch):
+			index++
+		case ch == '(' || ch == ')':
+			tokens = append(tokens, conditionToken{kind: conditionOperator, value: string(ch)})
+			index++
+		case ch == '!' || ch == '=' || ch == '&' || ch == '|':
+			operator := string(ch)
+			if index+1 < len(runes) {
+				operator += string(runes[index+1])
+			}
+			switch operator {
+			case "==", "!=", "&&", "||":
+				tokens = append(tokens, conditionToken{kind: conditionOperator, value: operator})
+				index += 2
+			default:
+				if ch != '!' {
+					return nil, newConditionError(condition, "unexpected '"+string(ch)+"'")
+				}
+				tokens = append(tokens, conditionToken{kind: conditionOperator, value: "!"})
+				index++
+			}
+		case ch == '\'' || ch == '"':
+			end := index + 1
+			for end < len(runes) && runes[end] != ch {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, newConditionError(condition, "unterminated string")
+			}
+			tokens = append(tokens, conditionToken{kind: conditionString, value: string(runes[index+1 : end])})
+			index = end + 1
+		default:
+			end := index
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()!=&|'\"", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, conditionToken{kind: conditionWord, value: string(runes[index:end])})
+			index = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type conditionParser struct {
+	condition  string
+	tokens     []conditionToken
+	position   int
+	parameters *config.ConfigParams
+}
+
+func (c *conditionParser) peek(value string) bool {
+	return c.position < len(c.tokens) &&
+		c.tokens[c.position].kind == conditionOperator &&
+		c.tokens[c.position].value == value
+}
+
+func (c *conditionParser) parseOr() (bool, error) {
+	result, err := c.parseAnd()
+	for err == nil && c.peek("||") {
+		c.position++
+		var right bool
+		right, err = c.parseAnd()
+		result = result || right
+	}
+	return result, err
+}
+
+func (c *conditionParser) parseAnd() (bool, error) {
+	result, err := c.parseUnary()
+	for err == nil && c.peek("&&") {
+		c.position++
+		var right bool
+		right, err = c.parseUnary()
+		result = result && right
+	}
+	return result, err
+}
+
+func (c *conditionParser) parseUnary() (bool, error) {
+	if c.peek("!") {
+		c.position++
+		result, err := c.parseUnary()
+		return !result, err
+	}
+	return c.parsePrimary()
+}
+
+func (c *conditionParser) parsePrimary() (bool, error) {
+	if c.position >= len(c.tokens) {
+		return false, c.newError("unexpected end of condition")
+	}
+
+	if c.peek("(") {
+		c.position++
+		result, err := c.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if !c.peek(")") {
+			return false, c.newError("missing ')'")
+		}
+		c.position++
+		return result, nil
+	}
+
+	token := c.tokens[c.position]
+	if token.kind == conditionOperator {
+		return false, c.newError("unexpected '" + token.value + "'")
+	}
+	c.position++
+
+	// Comparison of a parameter with a value
+	if c.peek("==") || c.peek("!=") {
+		equal := c.peek("==")
+		c.position++
+		if c.position >= len(c.tokens) || c.tokens[c.position].kind == conditionOperator {
+			return false, c.newError("missing value to compare " + token.value + " with")
+		}
+		value := c.tokens[c.position].value
+		c.position++
+
+		left := token.value
+		if token.kind == conditionWord {
+			left = c.parameters.GetAsString(token.value)
+		}
+		return (left == value) == equal, nil
+	}
+
+	if token.kind == conditionString {
+		return token.value != "", nil
+	}
+
+	switch strings.ToLower(token.value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	// Presence of a parameter
+	value, ok := c.parameters.GetAsNullableString(token.value)
+	if !ok || value == "" {
+		return false, nil
+	}
+	if enabled, ok := convert.BooleanConverter.ToNullableBoolean(value); ok {
+		return enabled, nil
+	}
+	return true, nil
+}
+
+func (c *conditionParser) newError(message string) error {
+	return newConditionError(c.condition, message)
+}
+
+func newConditionError(condition string, message string) error {
+	return errors.NewConfigError(
+		"", "INVALID_CONDITION", "Invalid condition '"+condition+"': "+message,
+	).WithDetails("condition", condition)
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"strings"
+	"time"
+
 	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
@@ -9,10 +12,27 @@ import (
 
 // ComponentConfig configuration of a component inside a container.
 // The configuration includes type information or descriptor, and component configuration parameters.
+//
+//	Configuration parameters:
+//		- open_timeout: timeout in milliseconds to open the component (default: container default)
+//		- close_timeout: timeout in milliseconds to close the component (default: container default)
+//		- restart: policy to restart the component after runtime failures
+//		- enabled: condition to create the component (default: true)
+//		- if: another condition to create the component, both conditions must be true
+//	see RestartPolicy
+//	see EvaluateCondition
 type ComponentConfig struct {
-	Descriptor *refer.Descriptor
-	Type       *reflect.TypeDescriptor
-	Config     *config.ConfigParams
+	Descriptor   *refer.Descriptor
+	Type         *reflect.TypeDescriptor
+	Config       *config.ConfigParams
+	OpenTimeout  time.Duration
+	CloseTimeout time.Duration
+	Restart      *RestartPolicy
+	Condition    string
+	// SensitiveKeys are keys of Config with values resolved from secret references
+	SensitiveKeys []string
+	// SensitiveValues are values of secret parameters rendered into Config
+	SensitiveValues []string
 }
 
 // NewComponentConfigFromDescriptor creates a new instance of the component configuration.
@@ -66,9 +86,95 @@ func ReadComponentConfigFromConfig(config *config.ConfigParams) (result *Compone
 		return nil, err
 	}
 
+	restart, err := ReadRestartPolicyFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ComponentConfig{
-		Descriptor: descriptor,
-		Type:       typ,
-		Config:     config,
+		Descriptor:   descriptor,
+		Type:         typ,
+		Config:       config,
+		OpenTimeout:  time.Duration(config.GetAsLongWithDefault("open_timeout", 0)) * time.Millisecond,
+		CloseTimeout: time.Duration(config.GetAsLongWithDefault("close_timeout", 0)) * time.Millisecond,
+		Restart:      restart,
+		Condition:    readCondition(config),
 	}, nil
 }
+
+func readCondition(config *config.ConfigParams) string {
+	conditions := make([]string, 0)
+	for _, key := range []string{"enabled", "if"} {
+		if condition := strings.TrimSpace(config.GetAsString(key)); condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	if len(conditions) > 1 {
+		return "(" + strings.Join(conditions, ") && (") + ")"
+	}
+	return ""
+}
+
+// IsEnabled checks if the component shall be created by evaluating its condition.
+//	see EvaluateCondition
+//	Parameters:
+//		- parameters *config.ConfigParams values of parameters referenced by the condition.
+//	Returns: bool, error true if the component is enabled and ConfigError when the condition is invalid.
+func (c *ComponentConfig) IsEnabled(parameters *config.ConfigParams) (bool, error) {
+	return EvaluateCondition(c.Condition, parameters)
+}
+
+// IsSensitiveKey checks if a value of the configuration key shall be redacted,
+// because it was resolved from a secret reference or the key looks like it holds a secret.
+//	see IsSecretKey
+//	Parameters:
+//		- key string a configuration key, for instance "credential.password".
+//	Returns: bool true if the value of the key shall be redacted.
+func (c *ComponentConfig) IsSensitiveKey(key string) bool {
+	if IsSecretKey(key) {
+		return true
+	}
+	for _, sensitiveKey := range c.SensitiveKeys {
+		if sensitiveKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRedactedConfig creates a copy of the component configuration parameters
+// with values of sensitive keys replaced by RedactedValue. Sensitive values
+// rendered into other keys, for instance a password in a connection uri, are replaced as well.
+//	see IsSensitiveKey
+//	Returns: *config.ConfigParams the redacted copy.
+func (c *ComponentConfig) GetRedactedConfig() *config.ConfigParams {
+	result := config.NewEmptyConfigParams()
+	if c.Config == nil {
+		return result
+	}
+
+	values := append(getSensitiveValues(c.Config, c.SensitiveKeys), c.SensitiveValues...)
+	for _, key := range c.Config.Keys() {
+		value := c.Config.GetAsString(key)
+		if value != "" && c.IsSensitiveKey(key) {
+			value = RedactedValue
+		} else {
+			value = redactText(value, values)
+		}
+		result.Put(key, value)
+	}
+	return result
+}
+
+// GetLocator gets a locator of the component.
+//	Returns: any the component descriptor or type descriptor when the descriptor is not set.
+func (c *ComponentConfig) GetLocator() any {
+	if c.Descriptor != nil {
+		return c.Descriptor
+	}
+	return c.Type
+}
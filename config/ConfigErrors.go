@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// CombineConfigErrors combines several configuration problems into one error,
+// so they can be reported at once.
+//	Parameters:
+//		- correlationId string transaction id to trace execution through call chain.
+//		- errs []error configuration problems.
+//	Returns: error nil when there are no problems, the problem itself when there is only one,
+//		or ConfigError with messages of all problems in "errors" details.
+func CombineConfigErrors(correlationId string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	messages := make([]string, len(errs))
+	for index, err := range errs {
+		messages[index] = err.Error()
+	}
+
+	return errors.NewConfigError(
+		correlationId, "INVALID_CONFIG",
+		fmt.Sprintf("Found %d configuration errors: %s", len(errs), strings.Join(messages, "; ")),
+	).WithCause(errs[0]).WithDetails("errors", messages)
+}
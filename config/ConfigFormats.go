@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/convert"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// componentsSection holds components in TOML and .properties files,
+// for instance [[components]] tables or components.0.descriptor keys
+const componentsSection = "components"
+
+// GetConfigFormat gets a format of configuration file by its extension.
+//	Parameters:
+//		- path string a path to configuration file.
+//	Returns: ConfigFormat, bool the file format and false when the extension is unknown.
+func GetConfigFormat(path string) (ConfigFormat, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigFormatJson, true
+	case ".yaml", ".yml":
+		return ConfigFormatYaml, true
+	case ".toml":
+		return ConfigFormatToml, true
+	case ".properties":
+		return ConfigFormatProperties, true
+	}
+	return "", false
+}
+
+var tomlTablePattern = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_.\-"' ]+\s*\]\]?$`)
+var yamlKeyPattern = regexp.MustCompile(`^"?[A-Za-z0-9_.\-]+"?\s*:(\s|$)`)
+var propertyPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+\s*[=:]`)
+
+// DetectConfigFormat detects a format of configuration file by its content.
+// It is used for files with unknown extensions. The first line that is not
+// empty, a comment or a template tag decides the format.
+//	Parameters:
+//		- correlationId string transaction id to trace execution through call chain.
+//		- path string a path to configuration file.
+//		- text string the file content.
+//	Returns: ConfigFormat, error the detected format and ConfigError when it cannot be detected.
+func DetectConfigFormat(correlationId string, path string, text string) (ConfigFormat, error) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "{{") {
+			continue
+		}
+
+		switch {
+		case tomlTablePattern.MatchString(line):
+			return ConfigFormatToml, nil
+		case strings.HasPrefix(line, "{") || strings.HasPrefix(line, "["):
+			return ConfigFormatJson, nil
+		case line == "---" || strings.HasPrefix(line, "- ") || line == "-" || yamlKeyPattern.MatchString(line):
+			return ConfigFormatYaml, nil
+		case propertyPattern.MatchString(line):
+			return ConfigFormatProperties, nil
+		}
+		break
+	}
+
+	return "", errors.NewConfigError(
+		correlationId, "UNKNOWN_FORMAT",
+		"Cannot detect format of config file "+path+", use .json, .yaml, .yml, .toml or .properties extension",
+	).WithDetails("path", path)
+}
+
+// parseConfig parses a rendered configuration file into configuration parameters.
+func parseConfig(correlationId string, path string, format ConfigFormat, text string) (*config.ConfigParams, error) {
+	var value any
+	var err error
+
+	switch format {
+	case ConfigFormatJson:
+		value = convert.JsonConverter.ToMap(text)
+	case ConfigFormatYaml:
+		err = yaml.Unmarshal([]byte(text), &value)
+	case ConfigFormatToml:
+		var document map[string]any
+		_, err = toml.Decode(text, &document)
+		value = document
+	case ConfigFormatProperties:
+		value, err = parseProperties(text)
+	default:
+		return nil, errors.NewConfigError(
+			correlationId, "UNSUPPORTED_FORMAT", "Unsupported configuration format "+string(format),
+		).WithDetails("format", format)
+	}
+	if err != nil {
+		return nil, errors.NewConfigError(
+			correlationId, "PARSE_FAILED", "Failed to parse "+string(format)+" config file "+path+": "+err.Error(),
+		).WithCause(err).WithDetails("path", path)
+	}
+
+	result := config.NewConfigParamsFromValue(value)
+
+	// TOML and .properties files have no top level lists, so components are kept in a section
+	if format == ConfigFormatToml || format == ConfigFormatProperties {
+		if names := result.GetSectionNames(); len(names) == 1 && names[0] == componentsSection {
+			result = result.GetSection(componentsSection)
+		}
+	}
+	return result, nil
+}
+
+// parseProperties parses Java-style .properties file into a flat map.
+// Keys are separated from values by "=", ":" or whitespace, lines started with "#" or "!"
+// are comments and lines ended with backslash continue on the next line.
+func parseProperties(text string) (map[string]any, error) {
+	result := make(map[string]any)
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for index := 0; index < len(lines); index++ {
+		number := index + 1
+		line := strings.TrimLeft(lines[index], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continued lines
+		for isContinued(line) && index+1 < len(lines) {
+			index++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[index], " \t\f")
+		}
+
+		end := 0
+		for end < len(line) && !strings.ContainsRune("=: \t\f", rune(line[end])) {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end > len(line) {
+			end = len(line)
+		}
+
+		value := strings.TrimLeft(line[end:], " \t\f")
+		if value != "" && (value[0] == '=' || value[0] == ':') {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+
+		key, err := unescapeProperty(line[:end])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+		if result[key], err = unescapeProperty(value); err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+	}
+
+	return result, nil
+}
+
+// isContinued checks if a line ends with an odd number of backslashes.
+func isContinued(line string) bool {
+	count := 0
+	for index := len(line) - 1; index >= 0 && line[index] == '\\'; index-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+func unescapeProperty(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+
+	builder := strings.Builder{}
+	for index := 0; index < len(value); index++ {
+		if value[index] != '\\' || index+1 >= len(value) {
+			builder.WriteByte(value[index])
+			continue
+		}
+
+		index++
+		switch value[index] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			if index+4 >= len(value) {
+				return "", fmt.Errorf("malformed \\u escape in %q", value)
+			}
+			code, err := strconv.ParseUint(value[index+1:index+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape in %q", value)
+			}
+			builder.WriteRune(rune(code))
+			index += 4
+		default:
+			builder.WriteByte(value[index])
+		}
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/mustache"
+	mparsers "github.com/pip-services3-gox/pip-services3-expressions-gox/mustache/parsers"
+)
+
+// inlineDefaultPattern matches placeholders with inline defaults, such as {{DB_HOST|localhost}}
+var inlineDefaultPattern = regexp.MustCompile(`\{\{\s*([^{}#^/!>&|\s]+)\s*\|([^{}\n]*)\}\}`)
+
+// parameterizeConfig renders a configuration template with mustache.
+// Placeholders with inline defaults are replaced with the defaults when parameters
+// have no values for them. In strict mode placeholders without values are returned
+// with files and lines where they are used, so they can be reported for all files at once.
+func parameterizeConfig(path string, text string,
+	parameters *config.ConfigParams, strict bool) (string, []string, error) {
+
+	variables := make(map[string]string)
+	if parameters != nil {
+		for key, value := range parameters.Value() {
+			variables[key] = value
+		}
+	}
+
+	template := mustache.NewMustacheTemplate()
+	text = inlineDefaultPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		match := inlineDefaultPattern.FindStringSubmatch(placeholder)
+		if value := template.GetVariable(variables, match[1]); value != nil && *value != "" {
+			return "{{" + match[1] + "}}"
+		}
+		return match[2]
+	})
+
+	if err := template.SetTemplate(text); err != nil {
+		return "", nil, err
+	}
+
+	var missing []string
+	if strict {
+		missing = findMissingVariables(template, template.ResultTokens(), variables, path, nil)
+	}
+
+	result, err := template.EvaluateWithVariables(variables)
+	return result, missing, err
+}
+
+// findMissingVariables collects placeholders without values in the rendered parts of the template.
+func findMissingVariables(template *mustache.MustacheTemplate, tokens []*mparsers.MustacheToken,
+	variables map[string]string, path string, missing []string) []string {
+
+	for _, token := range tokens {
+		switch token.Type() {
+		case mparsers.TokenVariable, mparsers.TokenEscapedVariable:
+			if template.GetVariable(variables, token.Value()) == nil {
+				// Lines of mustache tokens start from 0
+				missing = append(missing, fmt.Sprintf("%s at %s:%d", token.Value(), path, token.Line()+1))
+			}
+		case mparsers.TokenSection, mparsers.TokenInvertedSection:
+			value := template.GetVariable(variables, token.Value())
+			defined := value != nil && *value != ""
+			if defined == (token.Type() == mparsers.TokenSection) {
+				missing = findMissingVariables(template, token.Tokens(), variables, path, missing)
+			}
+		}
+	}
+	return missing
+}
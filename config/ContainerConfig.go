@@ -1,8 +1,11 @@
 package config
 
 import (
-	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	"sort"
+	"time"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 )
 
 // ContainerConfig Container configuration defined as a list of component configurations.
@@ -51,3 +54,76 @@ func ReadContainerConfigFromConfig(config *config.ConfigParams) (ContainerConfig
 
 	return result, nil
 }
+
+// GetDefaultTimeouts gets container-wide default timeouts to open and close components.
+// The defaults are defined by open_timeout and close_timeout parameters
+// in the context info section that describes the container.
+//	Returns: openTimeout time.Duration, closeTimeout time.Duration
+//		default timeouts or 0 when they are not set.
+func (c ContainerConfig) GetDefaultTimeouts() (openTimeout time.Duration, closeTimeout time.Duration) {
+	contextInfoDescriptor := refer.NewDescriptor("*", "context-info", "*", "*", "*")
+
+	for _, componentConfig := range c {
+		if componentConfig.Descriptor != nil && componentConfig.Descriptor.Match(contextInfoDescriptor) {
+			return componentConfig.OpenTimeout, componentConfig.CloseTimeout
+		}
+	}
+
+	return 0, 0
+}
+
+// GetShutdownOptions gets options to gracefully shutdown the process.
+// The options are defined by grace_period and pre_stop_delay parameters
+// in the context info section that describes the container.
+//	Returns: gracePeriod time.Duration, preStopDelay time.Duration
+//		shutdown options or 0 when they are not set.
+func (c ContainerConfig) GetShutdownOptions() (gracePeriod time.Duration, preStopDelay time.Duration) {
+	contextInfoDescriptor := refer.NewDescriptor("*", "context-info", "*", "*", "*")
+
+	for _, componentConfig := range c {
+		if componentConfig.Descriptor != nil && componentConfig.Descriptor.Match(contextInfoDescriptor) {
+			gracePeriod = time.Duration(componentConfig.Config.GetAsLongWithDefault("grace_period", 0)) * time.Millisecond
+			preStopDelay = time.Duration(componentConfig.Config.GetAsLongWithDefault("pre_stop_delay", 0)) * time.Millisecond
+			return gracePeriod, preStopDelay
+		}
+	}
+
+	return 0, 0
+}
+
+// GetSensitiveValues gets values resolved from secret references in all components.
+// Values of secret-looking keys are not included, since they may be common words.
+//	see ComponentConfig.SensitiveKeys
+//	Returns: []string sensitive values without duplicates.
+func (c ContainerConfig) GetSensitiveValues() []string {
+	result := make([]string, 0)
+	for _, componentConfig := range c {
+		if componentConfig.Config == nil {
+			continue
+		}
+		for _, key := range componentConfig.SensitiveKeys {
+			result = appendSensitiveValue(result, componentConfig.Config.GetAsString(key))
+		}
+	}
+	return result
+}
+
+// Redact replaces values resolved from secret references in a text with RedactedValue.
+// It is used to redact messages before they are logged.
+//	see GetSensitiveValues
+//	Parameters:
+//		- text string a text to redact.
+//	Returns: string the redacted text.
+func (c ContainerConfig) Redact(text string) string {
+	return redactText(text, c.GetSensitiveValues())
+}
+
+// RedactError creates a copy of the error with values resolved from secret references
+// replaced by RedactedValue.
+//	see Redact
+//	Parameters:
+//		- err error an error to redact.
+//	Returns: error the redacted error or the same error when it has no sensitive values.
+func (c ContainerConfig) RedactError(err error) error {
+	return redactError(err, c.GetSensitiveValues())
+}
@@ -2,20 +2,117 @@ package config
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
-	cconfig "github.com/pip-services3-gox/pip-services3-components-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/reflect"
 )
 
-// ContainerConfigReader Helper class that reads container configuration from JSON or YAML file.
-var ContainerConfigReader = &_TContainerConfigReader{}
+// ContainerConfigReader Helper class that reads container configuration from JSON, YAML, TOML or .properties file.
+var ContainerConfigReader = &_TContainerConfigReader{
+	secretResolvers: map[string]ISecretResolver{
+		"file": NewFileSecretResolver(),
+		"env":  NewEnvSecretResolver(),
+	},
+}
+
+type _TContainerConfigReader struct {
+	lock            sync.RWMutex
+	secretResolvers map[string]ISecretResolver
+	strict          bool
+}
+
+// SetStrict enables or disables strict parameterization. In strict mode reading
+// fails with one error that lists all placeholders without values with their files and lines,
+// including included files and profile overlays.
+// Placeholders with inline defaults and placeholders inside sections
+// that are not rendered are not reported. The mode applies to all reads,
+// use ReadFromFileWithOptions to enable it for a single read.
+//	Parameters:
+//		- strict bool true to enable strict mode.
+func (c *_TContainerConfigReader) SetStrict(strict bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.strict = strict
+}
+
+// IsStrict checks if strict parameterization is enabled.
+//	see SetStrict
+//	Returns: bool true if strict mode is enabled.
+func (c *_TContainerConfigReader) IsStrict() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.strict
+}
+
+// SetSecretResolver registers a resolver of secret references with the given scheme.
+// Resolvers for "file" and "env" schemes are registered by default.
+//	see ISecretResolver
+//	Parameters:
+//		- scheme string a scheme of secret references, for instance "vault" for ${vault:db/password}.
+//		- resolver ISecretResolver a resolver or nil to remove the resolver.
+func (c *_TContainerConfigReader) SetSecretResolver(scheme string, resolver ISecretResolver) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if resolver == nil {
+		delete(c.secretResolvers, scheme)
+	} else {
+		c.secretResolvers[scheme] = resolver
+	}
+}
 
-type _TContainerConfigReader struct{}
+// GetSecretResolver gets a resolver of secret references with the given scheme.
+//	Parameters:
+//		- scheme string a scheme of secret references.
+//	Returns: ISecretResolver the registered resolver or nil.
+func (c *_TContainerConfigReader) GetSecretResolver(scheme string) ISecretResolver {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.secretResolvers[scheme]
+}
 
-// ReadFromFile reads container configuration from JSON or YAML file.
-// The type of the file is determined by file extension.
+// ReadFromFile reads container configuration from JSON, YAML, TOML or .properties file.
+// The type of the file is determined by file extension. The type of files with
+// unknown extensions is detected by their content, see DetectConfigFormat.
+//
+// A configuration file may include components from other files with "include" entries.
+// Included paths are relative to the including file and may contain glob patterns.
+// Included components are placed at the position of the include entry and
+// are parameterized with the same parameters. A missing file is an error
+// unless the entry is marked as optional, patterns that match no files are skipped.
+//
+//	Example:
+//		- include: ./common/logging.yml
+//		- include:
+//			- ./common/counters.yml
+//			- ./conf.d/*.yml
+//		- include: ./local.yml
+//		  optional: true
+//
+// Configuration files are mustache templates rendered with the parameters.
+// Placeholders may have inline defaults used when parameters have no values for them,
+// for instance {{DB_HOST|localhost}}. Placeholders without values render empty
+// unless strict mode is enabled, see SetStrict.
+//
+// Values may reference secrets kept outside of the configuration as ${scheme:reference}.
+// References are resolved after parameterization by resolvers registered with SetSecretResolver.
+// Keys of resolved values are kept in ComponentConfig.SensitiveKeys, so the values
+// are redacted from errors, logs and written configurations.
+//
+//	Example:
+//		- descriptor: "pip-services:connection:default:default:1.0"
+//		  credential:
+//		    password: ${file:/run/secrets/db_password}
+//		    token: ${env:API_TOKEN}
+//
 //	Parameters:
 //		- ctx context.Context.
 //		- correlationId string transaction id to trace execution through call chain.
@@ -28,17 +125,12 @@ func (c *_TContainerConfigReader) ReadFromFile(ctx context.Context, correlationI
 		return nil, errors.NewConfigError(correlationId, "NO_PATH", "Missing config file path")
 	}
 
-	ext := filepath.Ext(path)
-
-	if ext == ".json" {
-		return c.ReadFromJsonFile(ctx, correlationId, path, parameters)
-	}
-
-	if ext == ".yaml" || ext == ".yml" {
-		return c.ReadFromYamlFile(ctx, correlationId, path, parameters)
+	state := newReadState(c.IsStrict())
+	config, err := c.readConfigParams(ctx, correlationId, path, parameters, state)
+	if err != nil {
+		return nil, state.getError(correlationId, err)
 	}
-
-	return c.ReadFromJsonFile(ctx, correlationId, path, parameters)
+	return c.readComponents(ctx, correlationId, config, parameters, []string{path}, state)
 }
 
 // ReadFromJsonFile reads container configuration from JSON file.
@@ -51,11 +143,12 @@ func (c *_TContainerConfigReader) ReadFromFile(ctx context.Context, correlationI
 func (c *_TContainerConfigReader) ReadFromJsonFile(ctx context.Context, correlationId string,
 	path string, parameters *config.ConfigParams) (ContainerConfig, error) {
 
-	config, err := cconfig.ReadJsonConfig(ctx, correlationId, path, parameters)
+	state := newReadState(c.IsStrict())
+	config, err := c.readConfigFile(ctx, correlationId, path, parameters, ConfigFormatJson, state)
 	if err != nil {
-		return nil, err
+		return nil, state.getError(correlationId, err)
 	}
-	return ReadContainerConfigFromConfig(config)
+	return c.readComponents(ctx, correlationId, config, parameters, []string{path}, state)
 }
 
 // ReadFromYamlFile reads container configuration from YAML file.
@@ -68,9 +161,557 @@ func (c *_TContainerConfigReader) ReadFromJsonFile(ctx context.Context, correlat
 func (c *_TContainerConfigReader) ReadFromYamlFile(ctx context.Context, correlationId string,
 	path string, parameters *config.ConfigParams) (ContainerConfig, error) {
 
-	config, err := cconfig.ReadYamlConfig(ctx, correlationId, path, parameters)
+	state := newReadState(c.IsStrict())
+	config, err := c.readConfigFile(ctx, correlationId, path, parameters, ConfigFormatYaml, state)
+	if err != nil {
+		return nil, state.getError(correlationId, err)
+	}
+	return c.readComponents(ctx, correlationId, config, parameters, []string{path}, state)
+}
+
+// ReadFromTomlFile reads container configuration from TOML file.
+// Components are defined as [[components]] tables or as tables named after components.
+//
+//	Example:
+//		[[components]]
+//		descriptor = "pip-services:logger:console:default:1.0"
+//		level = "{{LOG_LEVEL|info}}"
+//
+//	Parameters:
+//		- ctx context.Context.
+//		- correlationId string transaction id to trace execution through call chain.
+//		- path string a path to component configuration file.
+//		- parameters *config.ConfigParams values to parameters the configuration or null to skip parameterization.
+//	Returns: ContainerConfig, error the read container configuration and error
+func (c *_TContainerConfigReader) ReadFromTomlFile(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams) (ContainerConfig, error) {
+
+	state := newReadState(c.IsStrict())
+	config, err := c.readConfigFile(ctx, correlationId, path, parameters, ConfigFormatToml, state)
+	if err != nil {
+		return nil, state.getError(correlationId, err)
+	}
+	return c.readComponents(ctx, correlationId, config, parameters, []string{path}, state)
+}
+
+// ReadFromPropertiesFile reads container configuration from Java-style .properties file.
+// Components are defined by keys with "components." prefix and component index
+// or by keys prefixed with component names.
+//
+//	Example:
+//		components.0.descriptor=pip-services:logger:console:default:1.0
+//		components.0.level={{LOG_LEVEL|info}}
+//
+//	Parameters:
+//		- ctx context.Context.
+//		- correlationId string transaction id to trace execution through call chain.
+//		- path string a path to component configuration file.
+//		- parameters *config.ConfigParams values to parameters the configuration or null to skip parameterization.
+//	Returns: ContainerConfig, error the read container configuration and error
+func (c *_TContainerConfigReader) ReadFromPropertiesFile(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams) (ContainerConfig, error) {
+
+	state := newReadState(c.IsStrict())
+	config, err := c.readConfigFile(ctx, correlationId, path, parameters, ConfigFormatProperties, state)
+	if err != nil {
+		return nil, state.getError(correlationId, err)
+	}
+	return c.readComponents(ctx, correlationId, config, parameters, []string{path}, state)
+}
+
+// ReadFromFileWithProfiles reads container configuration from a configuration file
+// and merges overlays for the given profiles. An overlay for profile "dev"
+// of "config.yml" file is read from "config.dev.yml" file in the same folder.
+// Overlays are merged in the order of profiles, missing overlay files are skipped.
+//	see MergeConfigs
+//	Parameters:
+//		- ctx context.Context.
+//		- correlationId string transaction id to trace execution through call chain.
+//		- path string a path to component configuration file.
+//		- profiles []string names of profiles to apply.
+//		- parameters *config.ConfigParams values to parameters the configuration or null to skip parameterization.
+//	Returns: ContainerConfig, error the effective container configuration and error
+func (c *_TContainerConfigReader) ReadFromFileWithProfiles(ctx context.Context, correlationId string,
+	path string, profiles []string, parameters *config.ConfigParams) (ContainerConfig, error) {
+
+	return c.ReadFromFileWithOptions(ctx, correlationId, path, parameters, &ReadOptions{Profiles: profiles})
+}
+
+// ReadFromFileWithOptions reads container configuration from a configuration file
+// with options that apply only to this read.
+//	see ReadFromFileWithProfiles
+//	Parameters:
+//		- ctx context.Context.
+//		- correlationId string transaction id to trace execution through call chain.
+//		- path string a path to component configuration file.
+//		- parameters *config.ConfigParams values to parameters the configuration or null to skip parameterization.
+//		- options *ReadOptions read options or nil to use defaults.
+//	Returns: ContainerConfig, error the effective container configuration and error
+func (c *_TContainerConfigReader) ReadFromFileWithOptions(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams, options *ReadOptions) (ContainerConfig, error) {
+
+	if options == nil {
+		options = &ReadOptions{}
+	}
+	state := newReadState(options.Strict || c.IsStrict())
+
+	if path == "" {
+		return nil, errors.NewConfigError(correlationId, "NO_PATH", "Missing config file path")
+	}
+	config, err := c.readConfigParams(ctx, correlationId, path, parameters, state)
 	if err != nil {
+		return nil, state.getError(correlationId, err)
+	}
+	result, errs := c.readComponentsWithErrors(ctx, correlationId, config, parameters, []string{path}, state)
+
+	// Read all overlays before merging, so problems of all files are reported at once
+	overlays := make([][]*overlaySection, 0, len(options.Profiles))
+	for _, profile := range options.Profiles {
+		profilePath := GetProfilePath(path, profile)
+		if _, err := os.Stat(profilePath); err != nil {
+			continue
+		}
+
+		overlay, err := c.readConfigParams(ctx, correlationId, profilePath, parameters, state)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sections, overlayErrs := c.readOverlaySections(ctx, correlationId, overlay, parameters, []string{profilePath}, state)
+		errs = append(errs, overlayErrs...)
+		overlays = append(overlays, sections)
+	}
+	if err := state.getError(correlationId, CombineConfigErrors(correlationId, errs)); err != nil {
 		return nil, err
 	}
-	return ReadContainerConfigFromConfig(config)
+
+	for _, sections := range overlays {
+		sensitiveValues := result.GetSensitiveValues()
+		for _, section := range sections {
+			sensitiveValues = append(sensitiveValues, getSensitiveValues(section.config, section.sensitiveKeys)...)
+		}
+		if result, err = c.mergeConfig(result, sections); err != nil {
+			return nil, redactError(err, sensitiveValues)
+		}
+	}
+
+	setSecretParameterValues(result, parameters)
+	return result, nil
+}
+
+// readState holds state of a single read of a configuration file with its includes and overlays.
+type readState struct {
+	strict bool
+	// Placeholders without values found in strict mode in all read files
+	missing []string
+}
+
+func newReadState(strict bool) *readState {
+	return &readState{strict: strict}
+}
+
+// getError gets an error that lists all placeholders without values found in strict mode.
+// It takes precedence over other errors, since they may be caused by the empty values.
+// When all placeholders have values, the given error is returned.
+func (c *readState) getError(correlationId string, err error) error {
+	if len(c.missing) == 0 {
+		return err
+	}
+	return errors.NewConfigError(
+		correlationId, "MISSING_PARAMETERS",
+		fmt.Sprintf("Configuration has %d unresolved parameter(s): %s",
+			len(c.missing), strings.Join(c.missing, ", ")),
+	).WithDetails("parameters", c.missing)
+}
+
+func (c *_TContainerConfigReader) readConfigParams(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams, state *readState) (*config.ConfigParams, error) {
+
+	format, _ := GetConfigFormat(path)
+	return c.readConfigFile(ctx, correlationId, path, parameters, format, state)
+}
+
+// readConfigFile reads a configuration file, renders it as a template and parses the result.
+// The format of the file is detected by its content when it is empty.
+func (c *_TContainerConfigReader) readConfigFile(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams, format ConfigFormat, state *readState) (result *config.ConfigParams, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if err, ok = r.(error); !ok {
+				err = fmt.Errorf("pkg: %v", r)
+			}
+		}
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewFileError(
+			correlationId, "READ_FAILED", "Failed reading configuration "+path+": "+err.Error(),
+		).WithDetails("path", path).WithCause(err)
+	}
+
+	if format == "" {
+		if format, err = DetectConfigFormat(correlationId, path, string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	text, missing, err := parameterizeConfig(path, string(data), parameters, state.strict)
+	if err != nil {
+		return nil, err
+	}
+	state.missing = append(state.missing, missing...)
+	return parseConfig(correlationId, path, format, text)
+}
+
+// readComponents reads component configurations and replaces include entries
+// with components from included files. The chain holds paths of the files
+// that led to the current one, the last path is the current file.
+// It continues after errors, so all problems are reported at once.
+func (c *_TContainerConfigReader) readComponents(ctx context.Context, correlationId string,
+	config *config.ConfigParams, parameters *config.ConfigParams, chain []string, state *readState) (ContainerConfig, error) {
+
+	result, errs := c.readComponentsWithErrors(ctx, correlationId, config, parameters, chain, state)
+	if err := state.getError(correlationId, CombineConfigErrors(correlationId, errs)); err != nil {
+		return nil, err
+	}
+	setSecretParameterValues(result, parameters)
+	return result, nil
+}
+
+func (c *_TContainerConfigReader) readComponentsWithErrors(ctx context.Context, correlationId string,
+	config *config.ConfigParams, parameters *config.ConfigParams, chain []string, state *readState) (ContainerConfig, []error) {
+
+	result := make(ContainerConfig, 0)
+	errs := make([]error, 0)
+	if config == nil {
+		return result, errs
+	}
+
+	config, sensitiveKeys, secretErrs := c.resolveSecrets(ctx, correlationId, config)
+	for _, err := range secretErrs {
+		errs = append(errs, newIncludeError(correlationId, chain, err))
+	}
+	sensitiveValues := getSensitiveValues(config, sensitiveKeys)
+
+	names := config.GetSectionNames()
+	// Sort so components should come in a right order
+	sort.Strings(names)
+	for _, name := range names {
+		section := config.GetSection(name)
+		patterns := getIncludePatterns(section)
+		if len(patterns) == 0 {
+			componentConfig, err := ReadComponentConfigFromConfig(section)
+			if err != nil {
+				errs = append(errs, redactError(newIncludeError(correlationId, chain, err), sensitiveValues))
+				continue
+			}
+			componentConfig.SensitiveKeys = getSectionKeys(sensitiveKeys, name)
+			result = append(result, componentConfig)
+			continue
+		}
+
+		includePaths, includeErrs := getIncludePaths(correlationId, section, patterns, chain)
+		errs = append(errs, includeErrs...)
+		for _, includePath := range includePaths {
+			components, includeErrs := c.readIncludedFile(ctx, correlationId, includePath, parameters, chain, state)
+			result = append(result, components...)
+			errs = append(errs, includeErrs...)
+		}
+	}
+
+	return result, errs
+}
+
+func (c *_TContainerConfigReader) readIncludedFile(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams, chain []string, state *readState) (ContainerConfig, []error) {
+
+	chain = append(append([]string{}, chain...), path)
+	if err := checkIncludeCycle(correlationId, chain); err != nil {
+		return nil, []error{err}
+	}
+
+	config, err := c.readConfigParams(ctx, correlationId, path, parameters, state)
+	if err != nil {
+		return nil, []error{newIncludeError(correlationId, chain, err)}
+	}
+	return c.readComponentsWithErrors(ctx, correlationId, config, parameters, chain, state)
+}
+
+// overlaySection is a section of a profile overlay with keys of secrets resolved in it.
+type overlaySection struct {
+	config        *config.ConfigParams
+	sensitiveKeys []string
+}
+
+// readOverlaySections gets sections of a profile overlay and replaces include entries
+// with sections of included files, the same way as included components are read.
+// It continues after errors, so all problems are reported at once.
+func (c *_TContainerConfigReader) readOverlaySections(ctx context.Context, correlationId string,
+	config *config.ConfigParams, parameters *config.ConfigParams, chain []string, state *readState) ([]*overlaySection, []error) {
+
+	result := make([]*overlaySection, 0)
+	errs := make([]error, 0)
+	if config == nil {
+		return result, errs
+	}
+
+	config, sensitiveKeys, secretErrs := c.resolveSecrets(ctx, correlationId, config)
+	for _, err := range secretErrs {
+		errs = append(errs, newIncludeError(correlationId, chain, err))
+	}
+
+	names := config.GetSectionNames()
+	sort.Strings(names)
+	for _, name := range names {
+		section := config.GetSection(name)
+		patterns := getIncludePatterns(section)
+		if len(patterns) == 0 {
+			result = append(result, &overlaySection{
+				config:        section,
+				sensitiveKeys: getSectionKeys(sensitiveKeys, name),
+			})
+			continue
+		}
+
+		includePaths, includeErrs := getIncludePaths(correlationId, section, patterns, chain)
+		errs = append(errs, includeErrs...)
+		for _, includePath := range includePaths {
+			sections, includeErrs := c.readIncludedOverlay(ctx, correlationId, includePath, parameters, chain, state)
+			result = append(result, sections...)
+			errs = append(errs, includeErrs...)
+		}
+	}
+
+	return result, errs
+}
+
+func (c *_TContainerConfigReader) readIncludedOverlay(ctx context.Context, correlationId string,
+	path string, parameters *config.ConfigParams, chain []string, state *readState) ([]*overlaySection, []error) {
+
+	chain = append(append([]string{}, chain...), path)
+	if err := checkIncludeCycle(correlationId, chain); err != nil {
+		return nil, []error{err}
+	}
+
+	config, err := c.readConfigParams(ctx, correlationId, path, parameters, state)
+	if err != nil {
+		return nil, []error{newIncludeError(correlationId, chain, err)}
+	}
+	return c.readOverlaySections(ctx, correlationId, config, parameters, chain, state)
+}
+
+// checkIncludeCycle checks that the last file in the chain of includes was not included before.
+func checkIncludeCycle(correlationId string, chain []string) error {
+	path := chain[len(chain)-1]
+	absPath, _ := filepath.Abs(path)
+	for _, included := range chain[:len(chain)-1] {
+		if absIncluded, _ := filepath.Abs(included); absIncluded == absPath {
+			return errors.NewConfigError(
+				correlationId, "INCLUDE_CYCLE", "Cyclic include of config file "+path+" in "+strings.Join(chain, " -> "),
+			).WithDetails("include_chain", chain)
+		}
+	}
+	return nil
+}
+
+// getIncludePaths gets paths of files included by a section. Relative patterns are resolved
+// against the folder of the current file, glob patterns are expanded in sorted order
+// and missing files are skipped when the include is optional.
+func getIncludePaths(correlationId string, section *config.ConfigParams,
+	patterns []string, chain []string) ([]string, []error) {
+
+	path := chain[len(chain)-1]
+	optional := section.GetAsBoolean("optional")
+	result := make([]string, 0, len(patterns))
+	errs := make([]error, 0)
+	for _, pattern := range patterns {
+		includePath := pattern
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+
+		if strings.ContainsAny(includePath, "*?[") {
+			matches, err := filepath.Glob(includePath)
+			if err != nil {
+				errs = append(errs, newIncludeError(correlationId, chain,
+					errors.NewConfigError(correlationId, "INVALID_INCLUDE", "Invalid include pattern "+pattern).
+						WithCause(err)))
+				continue
+			}
+			sort.Strings(matches)
+			result = append(result, matches...)
+		} else if _, err := os.Stat(includePath); err != nil && optional {
+			continue
+		} else {
+			result = append(result, includePath)
+		}
+	}
+	return result, errs
+}
+
+// getIncludePatterns gets included paths from a single value or a list.
+// It returns an empty list when the section describes a component.
+func getIncludePatterns(section *config.ConfigParams) []string {
+	if pattern, ok := section.GetAsNullableString("include"); ok {
+		return []string{pattern}
+	}
+
+	include := section.GetSection("include")
+	names := include.Keys()
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	patterns := make([]string, 0, len(names))
+	for _, name := range names {
+		if pattern := include.GetAsString(name); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// newIncludeError wraps an error raised in an included file with the chain of includes
+// that led to the file. Errors of the top configuration file are returned as is.
+func newIncludeError(correlationId string, chain []string, err error) error {
+	if len(chain) < 2 {
+		return err
+	}
+	return errors.NewConfigError(
+		correlationId, "INCLUDE_FAILED",
+		"Failed to read config file "+chain[len(chain)-1]+" (include chain "+strings.Join(chain, " -> ")+"): "+err.Error(),
+	).WithCause(err).WithDetails("include_chain", chain)
+}
+
+// GetProfilePath gets a path to the overlay file of a profile.
+//	Parameters:
+//		- path string a path to the base configuration file.
+//		- profile string a profile name.
+//	Returns: string a path to the overlay file, for instance "config.dev.yml" for "config.yml".
+func GetProfilePath(path string, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// MergeConfigs merges overlays into base container configuration.
+// Overlays have the same format as the container configuration.
+// Components are matched by "id" parameter when it is set, otherwise by descriptor or type.
+// Parameters of matched components are deep-merged with overlay values taking precedence,
+// components with "remove" parameter set to true are removed and
+// other overlay components are added to the end.
+// Include entries are expanded only in overlays read from files, here they are rejected.
+//	see ReadFromFileWithProfiles
+//	Parameters:
+//		- base ContainerConfig the base container configuration.
+//		- overlays ...*config.ConfigParams overlays to merge in the given order.
+//	Returns: ContainerConfig, error the merged configuration and error
+func (c *_TContainerConfigReader) MergeConfigs(base ContainerConfig,
+	overlays ...*config.ConfigParams) (ContainerConfig, error) {
+
+	result := append(ContainerConfig{}, base...)
+
+	var err error
+	for _, overlay := range overlays {
+		if overlay == nil {
+			continue
+		}
+
+		names := overlay.GetSectionNames()
+		sort.Strings(names)
+		sections := make([]*overlaySection, 0, len(names))
+		for _, name := range names {
+			section := overlay.GetSection(name)
+			if len(getIncludePatterns(section)) > 0 {
+				return nil, errors.NewConfigError(
+					"", "INVALID_OVERLAY", "Overlay section "+name+" includes files, read overlays from files to include them",
+				).WithDetails("section", name)
+			}
+			sections = append(sections, &overlaySection{config: section})
+		}
+
+		if result, err = c.mergeConfig(result, sections); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// mergeConfig merges sections of a single overlay into the configuration.
+// Sensitive keys of the sections are added to sensitive keys of the merged components.
+func (c *_TContainerConfigReader) mergeConfig(base ContainerConfig,
+	sections []*overlaySection) (ContainerConfig, error) {
+
+	result := append(ContainerConfig{}, base...)
+	for _, overlay := range sections {
+		section := overlay.config
+		index, err := findMatchingComponent(result, section)
+		if err != nil {
+			return nil, err
+		}
+
+		if section.GetAsBoolean("remove") {
+			if index >= 0 {
+				result = append(result[:index], result[index+1:]...)
+			}
+			continue
+		}
+
+		componentSensitiveKeys := overlay.sensitiveKeys
+		if index >= 0 {
+			section = result[index].Config.Override(section)
+			componentSensitiveKeys = append(append([]string{}, result[index].SensitiveKeys...), componentSensitiveKeys...)
+		}
+
+		componentConfig, err := ReadComponentConfigFromConfig(section)
+		if err != nil {
+			return nil, err
+		}
+		componentConfig.SensitiveKeys = componentSensitiveKeys
+
+		if index >= 0 {
+			result[index] = componentConfig
+		} else {
+			result = append(result, componentConfig)
+		}
+	}
+
+	return result, nil
+}
+
+func findMatchingComponent(components ContainerConfig, section *config.ConfigParams) (int, error) {
+	id := section.GetAsString("id")
+	descriptor, err := refer.ParseDescriptorFromString(section.GetAsString("descriptor"))
+	if err != nil {
+		return -1, err
+	}
+	typ, err := reflect.ParseTypeDescriptorFromString(section.GetAsString("type"))
+	if err != nil {
+		return -1, err
+	}
+
+	for index, other := range components {
+		switch {
+		case id != "":
+			if other.Config.GetAsString("id") == id {
+				return index, nil
+			}
+		case descriptor != nil:
+			if other.Descriptor != nil && descriptor.Equals(other.Descriptor) {
+				return index, nil
+			}
+		case typ != nil:
+			if other.Type != nil && typ.Equals(other.Type) {
+				return index, nil
+			}
+		}
+	}
+
+	return -1, nil
 }
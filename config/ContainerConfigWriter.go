@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFormat format of configuration files.
+type ConfigFormat string
+
+//	Configuration formats
+//	ConfigFormatYaml: YAML document
+//	ConfigFormatJson: JSON document
+//	ConfigFormatToml: TOML document (read only)
+//	ConfigFormatProperties: Java-style .properties file (read only)
+const (
+	ConfigFormatYaml       ConfigFormat = "yaml"
+	ConfigFormatJson       ConfigFormat = "json"
+	ConfigFormatToml       ConfigFormat = "toml"
+	ConfigFormatProperties ConfigFormat = "properties"
+)
+
+// ParameterSource defines where a configuration parameter came from.
+type ParameterSource string
+
+//	Parameter sources
+//	ParameterSourceCli: command line arguments
+//	ParameterSourceEnvironment: environment variables
+//	ParameterSourceEnvFile: variables from .env file
+//	ParameterSourceDefault: default parameters set by the application
+const (
+	ParameterSourceCli         ParameterSource = "cli"
+	ParameterSourceEnvironment ParameterSource = "environment"
+	ParameterSourceEnvFile     ParameterSource = "env_file"
+	ParameterSourceDefault     ParameterSource = "default"
+)
+
+// RedactedValue replaces values of secret parameters in written configurations.
+const RedactedValue = "***"
+
+// ContainerConfigWriter helper class that writes effective container configuration as JSON or YAML.
+// Values of secret-looking keys and keys with resolved secrets are redacted.
+// Values of secret-looking parameters are redacted from all component values they were rendered into.
+//	see IsSecretKey
+//	see ComponentConfig.IsSensitiveKey
+var ContainerConfigWriter = &_TContainerConfigWriter{}
+
+type _TContainerConfigWriter struct{}
+
+// Write writes container configuration together with parameters used to parameterize it.
+// The written document has "parameters" section with value and source of each parameter
+// and "components" section with configurations of components.
+//	Parameters:
+//		- writer io.Writer a writer to write the configuration to.
+//		- format ConfigFormat a format of the written document.
+//		- conf ContainerConfig the container configuration.
+//		- parameters *config.ConfigParams parameters of the configuration or nil.
+//		- sources map[string]ParameterSource sources of the parameters or nil when they are unknown.
+//	Returns: error
+func (c *_TContainerConfigWriter) Write(writer io.Writer, format ConfigFormat, conf ContainerConfig,
+	parameters *config.ConfigParams, sources map[string]ParameterSource) error {
+
+	parametersValue := make(map[string]any)
+	if parameters != nil {
+		for _, key := range parameters.Keys() {
+			value := map[string]any{"value": redactValue(key, parameters.GetAsString(key))}
+			if source, ok := sources[key]; ok {
+				value["source"] = string(source)
+			}
+			parametersValue[key] = value
+		}
+	}
+
+	secretValues := getSecretParameterValues(parameters)
+	components := make([]any, 0, len(conf))
+	for _, componentConfig := range conf {
+		redacted := componentConfig.GetRedactedConfig()
+		for _, key := range redacted.Keys() {
+			redacted.Put(key, redactText(redacted.GetAsString(key), secretValues))
+		}
+		components = append(components, toNestedValue(redacted))
+	}
+
+	document := map[string]any{
+		"parameters": parametersValue,
+		"components": components,
+	}
+
+	switch format {
+	case ConfigFormatJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case ConfigFormatYaml, "":
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(data)
+		return err
+	default:
+		return errors.NewConfigError(
+			"", "UNSUPPORTED_FORMAT", "Unsupported configuration format "+string(format),
+		).WithDetails("format", format)
+	}
+}
+
+// IsSecretKey checks if a configuration key looks like it holds a secret,
+// i.e. its last segment contains password, secret or token or ends with key.
+//	Parameters:
+//		- key string a configuration key, for instance "connection.password".
+//	Returns: bool true if the value of the key shall be redacted.
+func IsSecretKey(key string) bool {
+	name := strings.ToLower(key)
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		name = name[index+1:]
+	}
+
+	for _, secret := range []string{"password", "passwd", "secret", "token"} {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return strings.HasSuffix(name, "key")
+}
+
+// RedactConfigParams creates a copy of configuration parameters
+// with values of secret-looking keys replaced by RedactedValue.
+//	see IsSecretKey
+//	Parameters:
+//		- params *config.ConfigParams configuration parameters to redact.
+//	Returns: *config.ConfigParams the redacted copy.
+func RedactConfigParams(params *config.ConfigParams) *config.ConfigParams {
+	result := config.NewEmptyConfigParams()
+	if params == nil {
+		return result
+	}
+
+	for _, key := range params.Keys() {
+		result.Put(key, redactValue(key, params.GetAsString(key)))
+	}
+	return result
+}
+
+func redactValue(key string, value string) string {
+	if value != "" && IsSecretKey(key) {
+		return RedactedValue
+	}
+	return value
+}
+
+// toNestedValue converts flat configuration parameters into nested maps.
+// Sections with sequential numeric keys are converted into lists.
+func toNestedValue(params *config.ConfigParams) any {
+	root := make(map[string]any)
+	for _, key := range params.Keys() {
+		node := root
+		segments := strings.Split(key, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := node[segment].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[segment] = child
+			}
+			node = child
+		}
+		if _, ok := node[segments[len(segments)-1]].(map[string]any); !ok {
+			node[segments[len(segments)-1]] = params.GetAsString(key)
+		}
+	}
+	return toListValues(root)
+}
+
+func toListValues(node map[string]any) any {
+	for key, value := range node {
+		if child, ok := value.(map[string]any); ok {
+			node[key] = toListValues(child)
+		}
+	}
+
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]any, len(node))
+	for _, key := range keys {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(list) || list[index] != nil {
+			return node
+		}
+		list[index] = node[key]
+	}
+	if len(list) == 0 {
+		return node
+	}
+	return list
+}
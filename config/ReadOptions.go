@@ -0,0 +1,10 @@
+package config
+
+// ReadOptions options to read container configuration from a file.
+//	see ContainerConfigReader.ReadFromFileWithOptions
+type ReadOptions struct {
+	// Profiles are names of profiles whose overlays are merged in the given order
+	Profiles []string
+	// Strict fails reading when placeholders have no values, in addition to ContainerConfigReader.SetStrict
+	Strict bool
+}
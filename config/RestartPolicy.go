@@ -0,0 +1,105 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// RestartMode defines how the container reacts on runtime failures of a component.
+type RestartMode string
+
+//	Restart modes
+//	RestartNever: failure of the component terminates the process
+//	RestartOnFailure: the component and components that depend on it are restarted
+//	RestartOneForAll: all components in the container are restarted
+const (
+	RestartNever     RestartMode = "never"
+	RestartOnFailure RestartMode = "on_failure"
+	RestartOneForAll RestartMode = "one_for_all"
+)
+
+// RestartPolicy policy to restart a component after a runtime failure.
+// The delay between restarts grows exponentially from Backoff up to MaxBackoff.
+// When the component fails more than MaxRestarts times, the failure terminates the process.
+// The number of restarts is reset when the component runs without failures for ResetAfter.
+//
+//	Configuration parameters:
+//		- restart:
+//			- policy: restart mode: never, on_failure or one_for_all (default: never)
+//			- max_restarts: maximum number of restarts (default: 3)
+//			- backoff: initial delay before restart in milliseconds (default: 1000)
+//			- max_backoff: maximum delay before restart in milliseconds (default: 30000)
+//			- reset_after: time without failures in milliseconds to reset the number of restarts
+//			  or 0 to never reset it (default: 60000)
+type RestartPolicy struct {
+	Mode        RestartMode
+	MaxRestarts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	ResetAfter  time.Duration
+}
+
+// NewRestartPolicy creates a new instance of the restart policy with default parameters.
+//	Parameters:
+//		- mode RestartMode a restart mode.
+//	Returns: *RestartPolicy
+func NewRestartPolicy(mode RestartMode) *RestartPolicy {
+	return &RestartPolicy{
+		Mode:        mode,
+		MaxRestarts: 3,
+		Backoff:     time.Second,
+		MaxBackoff:  30 * time.Second,
+		ResetAfter:  time.Minute,
+	}
+}
+
+// ReadRestartPolicyFromConfig reads the restart policy from "restart" section of component configuration.
+//	Parameters:
+//		- config *config.ConfigParams component configuration parameters.
+//	Returns: *RestartPolicy, error the restart policy or nil when it is not defined
+//		and ConfigError when the restart mode is unknown.
+func ReadRestartPolicyFromConfig(config *config.ConfigParams) (*RestartPolicy, error) {
+	section := config.GetSection("restart")
+	mode, ok := section.GetAsNullableString("policy")
+	if !ok || mode == "" {
+		return nil, nil
+	}
+
+	switch RestartMode(mode) {
+	case RestartNever, RestartOnFailure, RestartOneForAll:
+	default:
+		return nil, errors.NewConfigError(
+			"",
+			"BAD_RESTART_POLICY",
+			"Unknown restart policy "+mode+", expected never, on_failure or one_for_all",
+		).WithDetails("policy", mode)
+	}
+
+	policy := NewRestartPolicy(RestartMode(mode))
+	policy.MaxRestarts = section.GetAsIntegerWithDefault("max_restarts", policy.MaxRestarts)
+	policy.Backoff = time.Duration(
+		section.GetAsLongWithDefault("backoff", policy.Backoff.Milliseconds())) * time.Millisecond
+	policy.MaxBackoff = time.Duration(
+		section.GetAsLongWithDefault("max_backoff", policy.MaxBackoff.Milliseconds())) * time.Millisecond
+	policy.ResetAfter = time.Duration(
+		section.GetAsLongWithDefault("reset_after", policy.ResetAfter.Milliseconds())) * time.Millisecond
+
+	return policy, nil
+}
+
+// GetDelay calculates delay before the next restart.
+//	Parameters:
+//		- restarts int number of restarts already made.
+//	Returns: time.Duration the delay before the next restart.
+func (c *RestartPolicy) GetDelay(restarts int) time.Duration {
+	delay := c.Backoff
+	for index := 0; index < restarts && delay < c.MaxBackoff; index++ {
+		delay *= 2
+	}
+	if c.MaxBackoff > 0 && delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay
+}
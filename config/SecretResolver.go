@@ -0,0 +1,264 @@
+package config
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// ISecretResolver resolves references to secrets kept outside of the container configuration.
+// A reference is written in configuration values as ${scheme:reference}.
+//	see ContainerConfigReader.SetSecretResolver
+type ISecretResolver interface {
+	// Resolve gets the secret value by its reference.
+	//	Parameters:
+	//		- ctx context.Context
+	//		- correlationId string transaction id to trace execution through call chain.
+	//		- reference string a secret reference without scheme, for instance "/run/secrets/db_password".
+	//	Returns: string, error the secret value and error when the secret cannot be resolved.
+	Resolve(ctx context.Context, correlationId string, reference string) (string, error)
+}
+
+// SecretResolverFunc is a function that can be used as ISecretResolver,
+// for instance as a stand-in for a secret store in tests.
+//
+//	Example:
+//		ContainerConfigReader.SetSecretResolver("vault", SecretResolverFunc(
+//			func(ctx context.Context, correlationId string, reference string) (string, error) {
+//				return "secret", nil
+//			}))
+type SecretResolverFunc func(ctx context.Context, correlationId string, reference string) (string, error)
+
+// Resolve gets the secret value by calling the function.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- reference string a secret reference without scheme.
+//	Returns: string, error the secret value and error when the secret cannot be resolved.
+func (c SecretResolverFunc) Resolve(ctx context.Context, correlationId string, reference string) (string, error) {
+	return c(ctx, correlationId, reference)
+}
+
+// FileSecretResolver reads secrets from files, for instance Docker or Kubernetes secrets
+// mounted as ${file:/run/secrets/db_password}. Trailing line breaks are removed.
+type FileSecretResolver struct{}
+
+// NewFileSecretResolver creates a new instance of the file secret resolver.
+//	Returns: *FileSecretResolver
+func NewFileSecretResolver() *FileSecretResolver {
+	return &FileSecretResolver{}
+}
+
+// Resolve reads the secret from a file.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- reference string a path to the file.
+//	Returns: string, error the file content and FileError when the file cannot be read.
+func (c *FileSecretResolver) Resolve(ctx context.Context, correlationId string, reference string) (string, error) {
+	data, err := os.ReadFile(reference)
+	if err != nil {
+		return "", errors.NewFileError(
+			correlationId, "READ_FAILED", "Failed to read secret file "+reference,
+		).WithCause(err).WithDetails("path", reference)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// EnvSecretResolver reads secrets from environment variables referenced as ${env:NAME}.
+type EnvSecretResolver struct{}
+
+// NewEnvSecretResolver creates a new instance of the environment secret resolver.
+//	Returns: *EnvSecretResolver
+func NewEnvSecretResolver() *EnvSecretResolver {
+	return &EnvSecretResolver{}
+}
+
+// Resolve reads the secret from an environment variable.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- reference string a name of the environment variable.
+//	Returns: string, error the variable value and ConfigError when the variable is not set.
+func (c *EnvSecretResolver) Resolve(ctx context.Context, correlationId string, reference string) (string, error) {
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", errors.NewConfigError(
+			correlationId, "MISSING_SECRET", "Environment variable "+reference+" is not set",
+		).WithDetails("name", reference)
+	}
+	return value, nil
+}
+
+var secretReferencePattern = regexp.MustCompile(`\$\{([A-Za-z][A-Za-z0-9_-]*):([^}]+)\}`)
+
+// resolveSecrets replaces secret references in configuration values with resolved secrets
+// and returns keys of the replaced values, so they can be redacted.
+// It continues after errors, so all problems are reported at once.
+func (c *_TContainerConfigReader) resolveSecrets(ctx context.Context, correlationId string,
+	conf *config.ConfigParams) (*config.ConfigParams, []string, []error) {
+
+	sensitiveKeys := make([]string, 0)
+	errs := make([]error, 0)
+	if conf == nil {
+		return conf, sensitiveKeys, errs
+	}
+
+	result := config.NewEmptyConfigParams()
+	for _, key := range conf.Keys() {
+		value := conf.GetAsString(key)
+		resolved := false
+		value = secretReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+			match := secretReferencePattern.FindStringSubmatch(reference)
+			secret, err := c.resolveSecret(ctx, correlationId, match[1], match[2])
+			if err != nil {
+				errs = append(errs, errors.NewConfigError(
+					correlationId, "SECRET_FAILED", "Failed to resolve secret "+reference+" in "+key+": "+err.Error(),
+				).WithCause(err).WithDetails("key", key).WithDetails("reference", reference))
+				return reference
+			}
+			resolved = true
+			return secret
+		})
+		if resolved {
+			sensitiveKeys = append(sensitiveKeys, key)
+		}
+		result.Put(key, value)
+	}
+	return result, sensitiveKeys, errs
+}
+
+func (c *_TContainerConfigReader) resolveSecret(ctx context.Context, correlationId string,
+	scheme string, reference string) (string, error) {
+
+	resolver := c.GetSecretResolver(scheme)
+	if resolver == nil {
+		return "", errors.NewConfigError(
+			correlationId, "UNKNOWN_SECRET_SCHEME", "No secret resolver is registered for scheme "+scheme,
+		).WithDetails("scheme", scheme)
+	}
+	return resolver.Resolve(ctx, correlationId, reference)
+}
+
+// getSectionKeys gets keys of a section from keys of the whole configuration.
+func getSectionKeys(keys []string, section string) []string {
+	result := make([]string, 0)
+	for _, key := range keys {
+		if strings.HasPrefix(key, section+".") {
+			result = append(result, key[len(section)+1:])
+		}
+	}
+	return result
+}
+
+// getSensitiveValues gets values of sensitive keys.
+func getSensitiveValues(conf *config.ConfigParams, sensitiveKeys []string) []string {
+	result := make([]string, 0)
+	for _, key := range sensitiveKeys {
+		result = appendSensitiveValue(result, conf.GetAsString(key))
+	}
+	return result
+}
+
+// getSecretParameterValues gets values of parameters with secret-looking names.
+//	see IsSecretKey
+func getSecretParameterValues(parameters *config.ConfigParams) []string {
+	result := make([]string, 0)
+	if parameters == nil {
+		return result
+	}
+	for _, key := range parameters.Keys() {
+		if IsSecretKey(key) {
+			result = appendSensitiveValue(result, parameters.GetAsString(key))
+		}
+	}
+	return result
+}
+
+// setSecretParameterValues keeps values of secret parameters rendered into component configurations
+// in ComponentConfig.SensitiveValues, so they are redacted wherever they were rendered.
+func setSecretParameterValues(conf ContainerConfig, parameters *config.ConfigParams) {
+	values := getSecretParameterValues(parameters)
+	for _, componentConfig := range conf {
+		componentConfig.SensitiveValues = make([]string, 0)
+		if componentConfig.Config == nil {
+			continue
+		}
+		for _, key := range componentConfig.Config.Keys() {
+			for _, value := range values {
+				if strings.Contains(componentConfig.Config.GetAsString(key), value) {
+					componentConfig.SensitiveValues = appendSensitiveValue(componentConfig.SensitiveValues, value)
+				}
+			}
+		}
+	}
+}
+
+// appendSensitiveValue adds a non-empty value to sensitive values without duplicates.
+func appendSensitiveValue(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, other := range values {
+		if other == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// redactText replaces sensitive values in a text with RedactedValue.
+// Longer values are replaced first, so values that contain other values are redacted completely.
+func redactText(text string, values []string) string {
+	if len(values) == 0 {
+		return text
+	}
+
+	values = append([]string{}, values...)
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, RedactedValue)
+	}
+	return text
+}
+
+// redactError creates a copy of the error with sensitive values replaced by RedactedValue.
+func redactError(err error, values []string) error {
+	if err == nil || len(values) == 0 {
+		return err
+	}
+
+	appErr, ok := err.(*errors.ApplicationError)
+	if !ok {
+		if message := redactText(err.Error(), values); message != err.Error() {
+			return errors.NewConfigError("", "INVALID_CONFIG", message)
+		}
+		return err
+	}
+
+	result := *appErr
+	result.Message = redactText(appErr.Message, values)
+	result.Cause = redactText(appErr.Cause, values)
+	if appErr.Details != nil {
+		result.Details = make(map[string]any, len(appErr.Details))
+		for key, value := range appErr.Details {
+			switch detail := value.(type) {
+			case string:
+				value = redactText(detail, values)
+			case []string:
+				redacted := make([]string, len(detail))
+				for index, item := range detail {
+					redacted[index] = redactText(item, values)
+				}
+				value = redacted
+			}
+			result.Details[key] = value
+		}
+	}
+	return &result
+}
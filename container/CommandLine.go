@@ -0,0 +1,308 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+
+	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// CommandLineFlag describes a flag accepted by the command line.
+// A flag without ValueName is a boolean flag.
+//
+//	Example:
+//		flag := &CommandLineFlag{
+//			Name:        "port",
+//			Aliases:     []string{"P"},
+//			ValueName:   "number",
+//			Description: "HTTP port",
+//			Default:     "8080",
+//		}
+type CommandLineFlag struct {
+	// Name of the flag without dashes, for instance "config" for --config
+	Name string
+	// Aliases are other names of the flag, one letter aliases are used with one dash, for instance "c" for -c
+	Aliases []string
+	// ValueName is a name of the flag value shown in help or "" for boolean flags
+	ValueName string
+	// Description is shown in help
+	Description string
+	// Default value returned when the flag is not set
+	Default string
+	// Repeated flags collect all their values
+	Repeated bool
+	// OptionalValue flags can be used without value, their value can only be one of Values
+	OptionalValue bool
+	// Values are allowed flag values or empty to allow any value
+	Values []string
+}
+
+// CommandLine parses POSIX-style command line arguments with registered flags.
+// Flags are given as --name value, --name=value, -n value, -n=value or -nvalue.
+// Arguments after "--" and arguments that are not flags are positional.
+// Unknown and malformed flags are reported as errors.
+//
+//	Example:
+//		commandLine := NewCommandLine()
+//		commandLine.AddFlag(&CommandLineFlag{Name: "config", Aliases: []string{"c"}, ValueName: "path"})
+//		err := commandLine.Parse([]string{"--config=./config.yml"})
+//		path := commandLine.GetValue("config")
+type CommandLine struct {
+	flags  []*CommandLineFlag
+	values map[string][]string
+	args   []string
+}
+
+// NewCommandLine creates a new command line without flags.
+//	Returns: *CommandLine
+func NewCommandLine() *CommandLine {
+	return &CommandLine{
+		flags:  make([]*CommandLineFlag, 0),
+		values: make(map[string][]string),
+		args:   make([]string, 0),
+	}
+}
+
+// AddFlag registers a flag. A flag with the same name replaces the registered one.
+//	Parameters:
+//		- flag *CommandLineFlag a flag to register.
+func (c *CommandLine) AddFlag(flag *CommandLineFlag) {
+	for index, other := range c.flags {
+		if other.Name == flag.Name {
+			c.flags[index] = flag
+			return
+		}
+	}
+	c.flags = append(c.flags, flag)
+}
+
+// GetFlag gets a registered flag by its name or alias.
+//	Parameters:
+//		- name string a name or alias of the flag.
+//	Returns: *CommandLineFlag the flag or nil when it is not registered.
+func (c *CommandLine) GetFlag(name string) *CommandLineFlag {
+	for _, flag := range c.flags {
+		if flag.Name == name {
+			return flag
+		}
+		for _, alias := range flag.Aliases {
+			if alias == name {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// GetFlags gets registered flags.
+//	Returns: []*CommandLineFlag flags in the order they were registered.
+func (c *CommandLine) GetFlags() []*CommandLineFlag {
+	return append([]*CommandLineFlag{}, c.flags...)
+}
+
+// Parse parses command line arguments. Results of the previous parsing are cleared.
+//	Parameters:
+//		- args []string command line arguments.
+//	Returns: error BadRequestError when a flag is unknown or malformed.
+func (c *CommandLine) Parse(args []string) error {
+	c.values = make(map[string][]string)
+	c.args = make([]string, 0)
+
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+
+		if arg == "--" {
+			c.args = append(c.args, args[index+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			c.args = append(c.args, arg)
+			continue
+		}
+
+		name, value, hasValue := c.splitFlag(arg)
+		flag := c.GetFlag(name)
+		if name == "" || strings.HasPrefix(name, "-") {
+			return c.newError("INVALID_FLAG", "Invalid flag "+arg)
+		}
+		if flag == nil {
+			return c.newError("UNKNOWN_FLAG", "Unknown flag "+arg)
+		}
+
+		if flag.ValueName == "" {
+			// Boolean flag
+			if hasValue {
+				if _, ok := cconv.BooleanConverter.ToNullableBoolean(value); !ok {
+					return c.newError("INVALID_FLAG_VALUE", "Flag "+arg+" expects true or false")
+				}
+			} else {
+				value = "true"
+			}
+		} else if !hasValue {
+			next := ""
+			if index < len(args)-1 {
+				next = args[index+1]
+			}
+
+			if flag.OptionalValue {
+				if next != "" && containsString(flag.Values, next) {
+					value = next
+					index++
+				}
+			} else {
+				if index >= len(args)-1 {
+					return c.newError("MISSING_FLAG_VALUE", "Flag "+arg+" requires a value")
+				}
+				value = next
+				index++
+			}
+		}
+
+		if value != "" && len(flag.Values) > 0 && !containsString(flag.Values, value) {
+			return c.newError("INVALID_FLAG_VALUE",
+				fmt.Sprintf("Flag %s expects one of %s", arg, strings.Join(flag.Values, ", ")))
+		}
+
+		if flag.Repeated {
+			c.values[flag.Name] = append(c.values[flag.Name], value)
+		} else {
+			c.values[flag.Name] = []string{value}
+		}
+	}
+
+	return nil
+}
+
+// splitFlag splits a flag argument into name and value.
+func (c *CommandLine) splitFlag(arg string) (name string, value string, hasValue bool) {
+	if strings.HasPrefix(arg, "--") {
+		name = arg[2:]
+	} else {
+		name = arg[1:]
+	}
+
+	flagName := name
+	if position := strings.Index(name, "="); position >= 0 {
+		flagName = name[:position]
+	}
+
+	// Short flag with attached value, for instance -cconfig.yml
+	if !strings.HasPrefix(arg, "--") && len(flagName) > 1 && c.GetFlag(flagName) == nil {
+		if flag := c.GetFlag(name[:1]); flag != nil && flag.ValueName != "" {
+			return name[:1], name[1:], true
+		}
+	}
+
+	if flagName != name {
+		return flagName, name[len(flagName)+1:], true
+	}
+
+	return name, "", false
+}
+
+func (c *CommandLine) newError(code string, message string) error {
+	return cerr.NewBadRequestError("", code, message)
+}
+
+// IsSet checks if a flag was set in the parsed arguments.
+//	Parameters:
+//		- name string a name or alias of the flag.
+//	Returns: bool true if the flag was set.
+func (c *CommandLine) IsSet(name string) bool {
+	flag := c.GetFlag(name)
+	if flag == nil {
+		return false
+	}
+	_, ok := c.values[flag.Name]
+	return ok
+}
+
+// GetValue gets the last value of a flag.
+//	Parameters:
+//		- name string a name or alias of the flag.
+//	Returns: string the flag value or its default value when the flag was not set.
+func (c *CommandLine) GetValue(name string) string {
+	flag := c.GetFlag(name)
+	if flag == nil {
+		return ""
+	}
+	values := c.values[flag.Name]
+	if len(values) == 0 || values[len(values)-1] == "" {
+		return flag.Default
+	}
+	return values[len(values)-1]
+}
+
+// GetValues gets all values of a repeated flag.
+//	Parameters:
+//		- name string a name or alias of the flag.
+//	Returns: []string values in the order they were given.
+func (c *CommandLine) GetValues(name string) []string {
+	flag := c.GetFlag(name)
+	if flag == nil {
+		return []string{}
+	}
+	return append([]string{}, c.values[flag.Name]...)
+}
+
+// GetArgs gets positional arguments.
+//	Returns: []string arguments that are not flags.
+func (c *CommandLine) GetArgs() []string {
+	return append([]string{}, c.args...)
+}
+
+// Usage generates help for the registered flags.
+//	Returns: string the help text with one line per flag.
+func (c *CommandLine) Usage() string {
+	names := make([]string, len(c.flags))
+	width := 0
+	for index, flag := range c.flags {
+		parts := make([]string, 0, len(flag.Aliases)+1)
+		for _, alias := range flag.Aliases {
+			if len(alias) == 1 {
+				parts = append(parts, "-"+alias)
+			}
+		}
+		parts = append(parts, "--"+flag.Name)
+		for _, alias := range flag.Aliases {
+			if len(alias) > 1 {
+				parts = append(parts, "--"+alias)
+			}
+		}
+
+		name := strings.Join(parts, ", ")
+		switch {
+		case flag.OptionalValue:
+			name += " [" + strings.Join(flag.Values, "|") + "]"
+		case flag.ValueName != "":
+			name += " <" + flag.ValueName + ">"
+		}
+		names[index] = name
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	builder := strings.Builder{}
+	for index, flag := range c.flags {
+		description := flag.Description
+		if flag.Default != "" {
+			description += " (default: " + flag.Default + ")"
+		}
+		if flag.Repeated {
+			description += ", can be repeated"
+		}
+		builder.WriteString(fmt.Sprintf("  %-*s  %s\n", width, names[index], description))
+	}
+	return builder.String()
+}
+
+func containsString(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package container
+
+import (
+	"fmt"
+
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+)
+
+// DefaultFactoryName is a name of the factory with default container components.
+const DefaultFactoryName = "default"
+
+// ComponentDescription describes a configured component and how it is created.
+//	see Container.Describe
+type ComponentDescription struct {
+	// Config is the component configuration
+	Config *config.ComponentConfig
+	// Enabled is false when the component condition is false
+	Enabled bool
+	// Factory is a name of the factory that creates the component
+	// or "" when the component is created by its type or no factory can create it
+	Factory string
+}
+
+// String gets a human readable description of the component.
+// Values of sensitive keys are redacted.
+//	Returns: string the component locator and how it is created.
+func (c *ComponentDescription) String() string {
+	var result string
+	switch {
+	case c.Config.Type != nil:
+		result = fmt.Sprintf("%s created by type", c.redact(c.Config.Type, "type"))
+	case c.Factory != "":
+		result = fmt.Sprintf("%s created by %s factory", c.redact(c.Config.Descriptor, "descriptor"), c.Factory)
+	default:
+		result = fmt.Sprintf("%s has no factory", c.redact(c.Config.Descriptor, "descriptor"))
+	}
+
+	if !c.Enabled {
+		result += fmt.Sprintf(" (disabled: %s)", c.redact(c.Config.Condition, "enabled", "if"))
+	}
+	return result
+}
+
+// redact formats a value read from the configuration keys or replaces it
+// with config.RedactedValue when any of the keys is sensitive.
+func (c *ComponentDescription) redact(value any, keys ...string) string {
+	for _, key := range keys {
+		if c.Config.IsSensitiveKey(key) {
+			return config.RedactedValue
+		}
+	}
+	return fmt.Sprint(value)
+}
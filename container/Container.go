@@ -3,11 +3,17 @@ package container
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
 	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	creflect "github.com/pip-services3-gox/pip-services3-commons-gox/reflect"
+	crun "github.com/pip-services3-gox/pip-services3-commons-gox/run"
 	cbuild "github.com/pip-services3-gox/pip-services3-components-gox/build"
 	"github.com/pip-services3-gox/pip-services3-components-gox/info"
 	"github.com/pip-services3-gox/pip-services3-components-gox/log"
@@ -46,7 +52,15 @@ import (
 //		name: the context (container or process) name
 //		description: human-readable description of the context
 //		properties: entire section of additional descriptive properties
+//		open_timeout: default timeout in milliseconds to open each component
+//		close_timeout: default timeout in milliseconds to close each component
 //		- ...
+//
+//	Each component section can override the default timeouts
+//	with its own open_timeout and close_timeout parameters.
+//	A component section can also define a restart section to restart the component
+//	after it reports a runtime failure with run.SendShutdownSignalWithErr,
+//	see config.RestartPolicy.
 //	Example:
 //		======= config.yml ========
 //		- descriptor: mygroup:mycomponent1:default:default:1.0
@@ -71,20 +85,59 @@ import (
 type Container struct {
 	logger          log.ILogger
 	factories       *cbuild.CompositeFactory
+	namedFactories  []namedFactory
 	info            *info.ContextInfo
 	config          config.ContainerConfig
 	References      *refer.ContainerReferences
 	referenceable   crefer.IReferenceable
 	unreferenceable crefer.IUnreferenceable
+	parallel        bool
+	maxConcurrency  int
+	openTimeout     time.Duration
+	closeTimeout    time.Duration
+	healthTimeout   time.Duration
+	// Path and parameters of the last read configuration file
+	configFilePath   string
+	configParameters *cconfig.ConfigParams
+	parameterSources map[string]config.ParameterSource
+	profiles         []string
+	strict           bool
+	events           *refer.LifecycleEvents
+	// Lifecycle state guarded by the lock
+	lock       sync.Mutex
+	state      ContainerState
+	transition chan struct{}
+	openCancel context.CancelFunc
+	// Parent and child containers
+	parent           *Container
+	children         []*Container
+	parentReferences crefer.IReferences
+	// Group of containers opened and closed with this container
+	group *ContainerGroup
+	// References between components recorded during the last open
+	graph *refer.ReferenceGraph
+	// Copy of the configuration to redact logged messages. It has a separate lock,
+	// since messages are logged while the lifecycle lock is held.
+	redactLock   sync.Mutex
+	redactConfig config.ContainerConfig
+}
+
+// namedFactory keeps a name of an added factory to describe components.
+type namedFactory struct {
+	name    string
+	factory cbuild.IFactory
 }
 
 // NewEmptyContainer creates a new empty instance of the container.
 //	Returns *Container
 func NewEmptyContainer() *Container {
 	return &Container{
-		logger:    log.NewNullLogger(),
-		factories: build.NewDefaultContainerFactory(),
-		info:      info.NewContextInfo(),
+		logger:        log.NewNullLogger(),
+		factories:     build.NewDefaultContainerFactory(),
+		info:          info.NewContextInfo(),
+		events:        refer.NewLifecycleEvents(),
+		state:         ContainerStateCreated,
+		healthTimeout: DefaultHealthCheckTimeout,
 	}
 }
 
@@ -127,10 +180,54 @@ func InheritContainer(name string, description string,
 //		- ctx context.Context
 //		- config *cconfig.ConfigParams configuration parameters to be set.
 func (c *Container) Configure(ctx context.Context, conf *cconfig.ConfigParams) {
-	c.config, _ = config.ReadContainerConfigFromConfig(conf)
+	containerConfig, _ := config.ReadContainerConfigFromConfig(conf)
+	c.setConfig(containerConfig)
+}
+
+// setConfig sets the container configuration and its copy used to redact logged messages.
+func (c *Container) setConfig(conf config.ContainerConfig) {
+	c.config = conf
+	c.redactLock.Lock()
+	defer c.redactLock.Unlock()
+	c.redactConfig = conf
+}
+
+// getRedactConfig gets the configuration to redact logged messages.
+func (c *Container) getRedactConfig() config.ContainerConfig {
+	c.redactLock.Lock()
+	defer c.redactLock.Unlock()
+	return c.redactConfig
+}
+
+// SetProfiles sets configuration profiles. When the configuration is read from a file,
+// overlay files of the profiles are merged into it, for instance
+// "config.dev.yml" and "config.local.yml" for "config.yml" and "dev", "local" profiles.
+// It shall be called before the configuration is read.
+//	see config.ContainerConfigReader.ReadFromFileWithProfiles
+//	Parameters:
+//		- profiles ...string names of profiles to apply in the given order.
+func (c *Container) SetProfiles(profiles ...string) {
+	c.profiles = profiles
 }
 
-// ReadConfigFromFile container configuration from JSON or YAML file and parameterizes it with given values.
+// SetStrict enables strict parameterization when the configuration is read from a file.
+// Reading fails when configuration placeholders have no values.
+// It shall be called before the configuration is read.
+//	see config.ReadOptions
+//	Parameters:
+//		- strict bool true to enable strict mode.
+func (c *Container) SetStrict(strict bool) {
+	c.strict = strict
+}
+
+func (c *Container) getReadOptions() *config.ReadOptions {
+	return &config.ReadOptions{Profiles: c.profiles, Strict: c.strict}
+}
+
+// ReadConfigFromFile container configuration from JSON, YAML, TOML or .properties file and parameterizes it with given values.
+// Overlays of the configuration profiles are merged into the configuration.
+//	see SetProfiles
+//	see SetStrict
 //	Parameters:
 //		- ctx context.Context
 //		- correlationId string transaction id to trace execution through call chain.
@@ -139,12 +236,204 @@ func (c *Container) Configure(ctx context.Context, conf *cconfig.ConfigParams) {
 func (c *Container) ReadConfigFromFile(ctx context.Context, correlationId string,
 	path string, parameters *cconfig.ConfigParams) error {
 
-	var err error
-	c.config, err = config.ContainerConfigReader.ReadFromFile(ctx, correlationId, path, parameters)
+	conf, err := config.ContainerConfigReader.ReadFromFileWithOptions(ctx, correlationId,
+		path, parameters, c.getReadOptions())
+	c.setConfig(conf)
 	//c.logger.Trace(correlationId, config.String())
+	c.configFilePath = path
+	c.configParameters = parameters
+	return err
+}
+
+// ReloadConfig re-reads the configuration file previously read by ReadConfigFromFile
+// and applies it to the container. Errors are logged, so callers such as
+// the SIGHUP handler may ignore them.
+//	see Reconfigure
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: error
+func (c *Container) ReloadConfig(ctx context.Context, correlationId string) error {
+	if c.configFilePath == "" {
+		err := cerr.NewInvalidStateError(
+			correlationId, "NO_CONFIG_FILE", "Container configuration was not read from file",
+		)
+		c.Logger().Error(ctx, correlationId, err, "Failed to reload container")
+		return err
+	}
+
+	conf, err := config.ContainerConfigReader.ReadFromFileWithOptions(ctx, correlationId,
+		c.configFilePath, c.configParameters, c.getReadOptions())
+	if err != nil {
+		// The container keeps running with the previous configuration
+		c.Logger().Error(ctx, correlationId, err, "Failed to read configuration to reload container")
+		return err
+	}
+
+	return c.Reconfigure(ctx, correlationId, conf)
+}
+
+// Reconfigure applies a new configuration to the container without restarting it.
+// When the container is opened, components removed from the configuration are closed and removed,
+// added components are created and opened, and components with changed configuration
+// are recreated or reconfigured in place when their configuration sets "reconfigurable" to true.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- conf config.ContainerConfig a new container configuration.
+//	Returns: error
+func (c *Container) Reconfigure(ctx context.Context, correlationId string, conf config.ContainerConfig) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setConfig(conf)
+
+	// Skip if container wasn't opened
+	if c.state != ContainerStateOpened {
+		return nil
+	}
+
+	c.logger.Trace(ctx, correlationId, "Reloading %s container", c.info.Name)
+
+	c.References.Runner.SetTimeouts(c.getDefaultTimeouts())
+	conf, err := c.getEnabledComponents(ctx, correlationId, conf)
+	if err == nil {
+		err = c.References.ReloadFromConfig(ctx, correlationId, conf)
+	}
+
+	if err == nil {
+		c.logger.Info(ctx, correlationId, "Container %s reloaded", c.info.Name)
+	} else {
+		c.logger.Error(ctx, correlationId, err, "Failed to reload container")
+	}
+
 	return err
 }
 
+// WriteConfig writes the effective container configuration after parameterization
+// with merged profiles and included files. Disabled components are skipped
+// and values of secret-looking keys are redacted.
+//	see config.ContainerConfigWriter
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- writer io.Writer a writer to write the configuration to.
+//		- format config.ConfigFormat a format of the written document.
+//	Returns: error
+func (c *Container) WriteConfig(ctx context.Context, correlationId string,
+	writer io.Writer, format config.ConfigFormat) error {
+
+	conf := make(config.ContainerConfig, 0, len(c.config))
+	for _, componentConfig := range c.config {
+		enabled, err := componentConfig.IsEnabled(c.configParameters)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			conf = append(conf, componentConfig)
+		}
+	}
+
+	return config.ContainerConfigWriter.Write(writer, format, conf, c.configParameters, c.parameterSources)
+}
+
+// getEnabledComponents evaluates component conditions against parameters
+// of the configuration file and skips disabled components.
+func (c *Container) getEnabledComponents(ctx context.Context, correlationId string,
+	conf config.ContainerConfig) (config.ContainerConfig, error) {
+
+	result := make(config.ContainerConfig, 0, len(conf))
+	for _, componentConfig := range conf {
+		enabled, err := componentConfig.IsEnabled(c.configParameters)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			c.logger.Info(ctx, correlationId, "Skipped component %s: condition %s is false",
+				componentConfig.GetLocator(), componentConfig.Condition)
+			continue
+		}
+		result = append(result, componentConfig)
+	}
+	return result, nil
+}
+
+// Validate checks the container configuration without creating or opening components.
+// It checks that component conditions are valid, each descriptor can be created
+// by a registered factory and each type can be resolved.
+// Factories defined as components in the configuration are not taken into account.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: []error all found problems or an empty list when the configuration is valid.
+func (c *Container) Validate(ctx context.Context, correlationId string) []error {
+	errs := make([]error, 0)
+
+	for _, componentConfig := range c.config {
+		if _, err := componentConfig.IsEnabled(c.configParameters); err != nil {
+			errs = append(errs, err)
+		}
+
+		if componentConfig.Type != nil {
+			if creflect.TypeReflector.GetTypeByDescriptor(componentConfig.Type) == nil {
+				errs = append(errs, cbuild.NewCreateError(
+					correlationId, fmt.Sprintf("Type %v of component cannot be resolved", componentConfig.Type),
+				).WithDetails("type", componentConfig.Type))
+			}
+		} else if c.factories.CanCreate(componentConfig.Descriptor) == nil {
+			errs = append(errs, cbuild.NewCreateError(
+				correlationId, fmt.Sprintf("No registered factory can create component %v", componentConfig.Descriptor),
+			).WithDetails("locator", componentConfig.Descriptor))
+		}
+	}
+
+	return errs
+}
+
+// Describe describes configured components and factories that create them
+// without creating or opening components.
+// Factories defined as components in the configuration are not taken into account.
+//	see AddNamedFactory
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: []*ComponentDescription, error descriptions of components in the configuration order
+//		and error when a component condition is invalid.
+func (c *Container) Describe(ctx context.Context, correlationId string) ([]*ComponentDescription, error) {
+	descriptions := make([]*ComponentDescription, 0, len(c.config))
+
+	for _, componentConfig := range c.config {
+		enabled, err := componentConfig.IsEnabled(c.configParameters)
+		if err != nil {
+			return nil, err
+		}
+
+		description := &ComponentDescription{
+			Config:  componentConfig,
+			Enabled: enabled,
+		}
+		if componentConfig.Type == nil {
+			description.Factory = c.getFactoryName(componentConfig.Descriptor)
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	return descriptions, nil
+}
+
+// getFactoryName finds a factory that creates a component in the same order as the composite factory.
+func (c *Container) getFactoryName(locator any) string {
+	for index := len(c.namedFactories) - 1; index >= 0; index-- {
+		if c.namedFactories[index].factory.CanCreate(locator) != nil {
+			return c.namedFactories[index].name
+		}
+	}
+	if c.factories.CanCreate(locator) != nil {
+		return DefaultFactoryName
+	}
+	return ""
+}
+
 func (c *Container) initReferences(ctx context.Context, references crefer.IReferences) {
 	contextInfoRef := references.GetOneOptional(
 		crefer.NewDescriptor("pip-services", "context-info",
@@ -176,12 +465,27 @@ func (c *Container) initReferences(ctx context.Context, references crefer.IRefer
 	)
 }
 
+func (c *Container) getDefaultTimeouts() (openTimeout time.Duration, closeTimeout time.Duration) {
+	openTimeout, closeTimeout = c.config.GetDefaultTimeouts()
+	if openTimeout <= 0 {
+		openTimeout = c.openTimeout
+	}
+	if closeTimeout <= 0 {
+		closeTimeout = c.closeTimeout
+	}
+	return openTimeout, closeTimeout
+}
+
 func (c *Container) Logger() log.ILogger {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	return c.logger
 }
 
 func (c *Container) SetLogger(logger log.ILogger) {
-	c.logger = logger
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.logger = newRedactingLogger(logger, c)
 }
 
 func (c *Container) Info() *info.ContextInfo {
@@ -193,28 +497,267 @@ func (c *Container) Info() *info.ContextInfo {
 //	Parameters:
 //		- factory IFactory a component factory to be added.
 func (c *Container) AddFactory(factory cbuild.IFactory) {
+	c.AddNamedFactory(fmt.Sprintf("%T", factory), factory)
+}
+
+// AddNamedFactory adds a factory to the container like AddFactory
+// and sets its name shown in component descriptions.
+//	see Describe
+//	Parameters:
+//		- name string a factory name.
+//		- factory IFactory a component factory to be added.
+func (c *Container) AddNamedFactory(name string, factory cbuild.IFactory) {
 	c.factories.Add(factory)
+	c.namedFactories = append(c.namedFactories, namedFactory{name: name, factory: factory})
+}
+
+// SetParallel enables or disables parallel opening and closing of components.
+// When enabled, components that do not depend on each other are opened and closed concurrently.
+// It shall be called before the container is opened.
+//	Parameters:
+//		- parallel bool true to enable parallel mode.
+//		- maxConcurrency int maximum number of components opened
+//			or closed at the same time or 0 for unlimited.
+func (c *Container) SetParallel(parallel bool, maxConcurrency int) {
+	c.parallel = parallel
+	c.maxConcurrency = maxConcurrency
+}
+
+// SetTimeouts sets default timeouts to open and close each component.
+// Timeouts defined in the container configuration take precedence over these values.
+// It shall be called before the container is opened.
+//	Parameters:
+//		- openTimeout time.Duration timeout to open a component or 0 for no timeout.
+//		- closeTimeout time.Duration timeout to close a component or 0 for no timeout.
+func (c *Container) SetTimeouts(openTimeout time.Duration, closeTimeout time.Duration) {
+	c.openTimeout = openTimeout
+	c.closeTimeout = closeTimeout
+}
+
+// SetHealthCheckTimeout sets maximum time to check health of a single component.
+// Components that do not complete their checks in time are reported as unhealthy.
+//	see DefaultHealthCheckTimeout
+//	Parameters:
+//		- timeout time.Duration timeout to check a component or 0 for no timeout.
+func (c *Container) SetHealthCheckTimeout(timeout time.Duration) {
+	c.healthTimeout = timeout
+}
+
+// AddListener registers a listener to receive lifecycle events of the container and its components.
+// Components that implement ILifecycleListener interface receive the events without registration.
+//	see refer.ILifecycleListener
+//	Parameters:
+//		- listener refer.ILifecycleListener a listener to be added.
+func (c *Container) AddListener(listener refer.ILifecycleListener) {
+	c.events.AddListener(listener)
+}
+
+// RemoveListener removes a previously registered lifecycle listener.
+//	Parameters:
+//		- listener refer.ILifecycleListener a listener to be removed.
+func (c *Container) RemoveListener(listener refer.ILifecycleListener) {
+	c.events.RemoveListener(listener)
+}
+
+// AddChild adds a child container. The child searches components that are missing
+// in its own configuration among components of this container, so it can share
+// loggers, counters, connections and other infrastructure components.
+// The child is opened and closed independently, but it shall be opened after this container
+// and it is automatically closed before this container is closed.
+//	Parameters:
+//		- child *Container a child container to be added.
+func (c *Container) AddChild(child *Container) {
+	child.lock.Lock()
+	child.parent = c
+	child.lock.Unlock()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.children = append(c.children, child)
+}
+
+// RemoveChild removes a previously added child container.
+// The removed child is not closed.
+//	Parameters:
+//		- child *Container a child container to be removed.
+func (c *Container) RemoveChild(child *Container) {
+	c.lock.Lock()
+	for index, other := range c.children {
+		if other == child {
+			c.children = append(c.children[:index], c.children[index+1:]...)
+			break
+		}
+	}
+	c.lock.Unlock()
+
+	child.lock.Lock()
+	defer child.lock.Unlock()
+	if child.parent == c {
+		child.parent = nil
+	}
+}
+
+// GetChildren gets child containers.
+//	Returns: []*Container a list of child containers.
+func (c *Container) GetChildren() []*Container {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*Container{}, c.children...)
+}
+
+// getParentReferences gets references of the parent container
+// or references shared by the container group.
+func (c *Container) getParentReferences() (crefer.IReferences, bool) {
+	c.lock.Lock()
+	parent, parentReferences := c.parent, c.parentReferences
+	c.lock.Unlock()
+
+	if parent == nil {
+		return parentReferences, true
+	}
+
+	references := parent.getReferences()
+	if references == nil {
+		return nil, false
+	}
+	return references, true
+}
+
+func (c *Container) setParentReferences(references crefer.IReferences) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.parentReferences = references
+}
+
+func (c *Container) notifyContainerEvent(ctx context.Context, correlationId string,
+	eventType refer.LifecycleEventType, start time.Time, err error) {
+
+	c.events.Notify(ctx, correlationId, &refer.LifecycleEvent{
+		Type:      eventType,
+		Locator:   c.info.Name,
+		Component: c,
+		Time:      start,
+		Duration:  time.Since(start),
+		Error:     err,
+	})
+}
+
+// State gets the current state of the container lifecycle.
+//	Returns: ContainerState the current state.
+func (c *Container) State() ContainerState {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.state
 }
 
 // IsOpen checks if the component is opened.
 //	Returns bool true if the component has been opened and false otherwise.
 func (c *Container) IsOpen() bool {
-	return c.References != nil
+	return c.State() == ContainerStateOpened
+}
+
+// GetReferenceGraph gets lookups that components made through the references
+// they received while the container was last opened.
+//	Returns: *refer.ReferenceGraph the reference graph or nil when the container was never opened.
+func (c *Container) GetReferenceGraph() *refer.ReferenceGraph {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.graph
+}
+
+// WriteGraph writes references between components recorded while the container was last opened.
+// Edges are marked required or optional and lookups that found nothing are highlighted.
+//	see refer.ReferenceGraph
+//	Parameters:
+//		- writer io.Writer a writer to write the graph to.
+//		- format refer.GraphFormat a format of the graph.
+//	Returns: error InvalidStateError when the container was never opened.
+func (c *Container) WriteGraph(writer io.Writer, format refer.GraphFormat) error {
+	graph := c.GetReferenceGraph()
+	if graph == nil {
+		return cerr.NewInvalidStateError(
+			"", "NOT_OPENED", "Container shall be opened to record references between components",
+		)
+	}
+
+	output, err := graph.Export(format)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, output)
+	return err
+}
+
+func (c *Container) getReferences() *refer.ContainerReferences {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.References
+}
+
+// finishTransition completes the current opening or closing transition
+// and wakes up callers waiting for it.
+func (c *Container) finishTransition(state ContainerState) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.state = state
+	if state != ContainerStateOpened && c.openCancel != nil {
+		c.openCancel()
+		c.openCancel = nil
+	}
+	if c.transition != nil {
+		close(c.transition)
+		c.transition = nil
+	}
 }
 
 // Open the component.
+// The container can be opened when it was created or closed before.
+// The context passed to components is cancelled when the open is cancelled
+// by Close or when the container is closed.
 //	Parameters:
 //		- ctx context.Context
 //		- correlationId string transaction id to trace execution through call chain.
 //	Returns: error
 func (c *Container) Open(ctx context.Context, correlationId string) error {
-	var err error
-
-	if c.References != nil {
+	c.lock.Lock()
+	switch c.state {
+	case ContainerStateOpening, ContainerStateOpened:
+		c.lock.Unlock()
 		return cerr.NewInvalidStateError(
 			correlationId, "ALREADY_OPENED", "Container was already opened",
 		)
+	case ContainerStateFailed:
+		c.lock.Unlock()
+		return cerr.NewInvalidStateError(
+			correlationId, "CONTAINER_FAILED", "Container failed to open and shall be closed first",
+		)
+	case ContainerStateClosing:
+		c.lock.Unlock()
+		return cerr.NewInvalidStateError(
+			correlationId, "CONTAINER_CLOSING", "Container is being closed",
+		)
+	}
+
+	ctx, c.openCancel = context.WithCancel(ctx)
+	c.state = ContainerStateOpening
+	c.transition = make(chan struct{})
+	c.lock.Unlock()
+
+	state := ContainerStateFailed
+	defer func() {
+		c.finishTransition(state)
+	}()
+
+	err := c.open(ctx, correlationId)
+	if err == nil {
+		state = ContainerStateOpened
 	}
+	return err
+}
+
+func (c *Container) open(ctx context.Context, correlationId string) error {
+	var err error
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -229,13 +772,38 @@ func (c *Container) Open(ctx context.Context, correlationId string) error {
 		}
 	}()
 
+	// Child containers use components of the opened parent
+	parentReferences, ok := c.getParentReferences()
+	if !ok {
+		return cerr.NewInvalidStateError(
+			correlationId, "PARENT_NOT_OPENED", "Parent container shall be opened before its children",
+		)
+	}
+
 	c.logger.Trace(ctx, correlationId, "Starting container.")
+	start := time.Now()
+	c.notifyContainerEvent(ctx, correlationId, refer.ContainerStarting, start, nil)
 
 	// Create references with configured components
-	c.References = refer.NewContainerReferences()
+	references := refer.NewContainerReferences()
+	references.Linker.Graph = refer.NewReferenceGraph()
+	c.lock.Lock()
+	c.References = references
+	c.graph = references.Linker.Graph
+	c.lock.Unlock()
+	c.References.SetEvents(ctx, c.events)
+	c.References.Runner.SetParallel(c.parallel, c.maxConcurrency)
+	c.References.Runner.SetTimeouts(c.getDefaultTimeouts())
 	c.initReferences(ctx, c.References)
-	err = c.References.PutFromConfig(ctx, c.config)
+	if parentReferences != nil {
+		c.References.SetParent(parentReferences)
+	}
+	conf, err := c.getEnabledComponents(ctx, correlationId, c.config)
+	if err == nil {
+		err = c.References.PutFromConfig(ctx, conf)
+	}
 	if err != nil {
+		c.notifyContainerEvent(ctx, correlationId, refer.ContainerStarted, start, err)
 		return err
 	}
 
@@ -251,26 +819,85 @@ func (c *Container) Open(ctx context.Context, correlationId string) error {
 	}
 
 	// Get reference to logger
-	c.logger = log.NewCompositeLoggerFromReferences(ctx, c.References)
+	logger := newRedactingLogger(log.NewCompositeLoggerFromReferences(ctx, c.References), c)
+	c.lock.Lock()
+	c.logger = logger
+	c.lock.Unlock()
+
+	// Stop when Close was called while components were created
+	if ctx.Err() != nil {
+		err = cerr.NewInvalidStateError(
+			correlationId, "OPEN_CANCELLED", "Container open was cancelled",
+		).WithCause(ctx.Err())
+		c.notifyContainerEvent(ctx, correlationId, refer.ContainerStarted, start, err)
+		return err
+	}
 
 	// Open references
 	err = c.References.Open(ctx, correlationId)
+	if err == nil && c.group != nil {
+		// Open the group in the same transition, so Close cancels or closes it as well
+		c.group.SetLogger(c.logger)
+		c.group.SetParentReferences(c.References)
+		err = c.group.Open(ctx, correlationId)
+	}
+	c.notifyContainerEvent(ctx, correlationId, refer.ContainerStarted, start, err)
 	if err == nil {
 		c.logger.Info(ctx, correlationId, "Container %s started", c.info.Name)
 	} else {
 		c.logger.Fatal(ctx, correlationId, err, "Failed to start container")
-		_ = c.Close(ctx, correlationId)
+		// The open context may be cancelled by Close, but components shall still be closed
+		_ = c.close(newDetachedContext(ctx), correlationId)
 	}
 
 	return err
 }
 
 // Close component and frees used resources.
+// When the container is being opened, the open is cancelled
+// and the container is closed after it completes.
+// Concurrent calls wait until the container is closed.
 //	Parameters:
 //		- ctx context.Context
 //		- correlationId string transaction id to trace execution through call chain.
 //	Returns: error
 func (c *Container) Close(ctx context.Context, correlationId string) error {
+	c.lock.Lock()
+	for c.state == ContainerStateOpening || c.state == ContainerStateClosing {
+		closing := c.state == ContainerStateClosing
+		if !closing {
+			c.openCancel()
+		}
+
+		transition := c.transition
+		c.lock.Unlock()
+		<-transition
+		c.lock.Lock()
+
+		if closing {
+			c.lock.Unlock()
+			return nil
+		}
+	}
+
+	// Skip if container wasn't opened
+	if c.state != ContainerStateOpened && c.state != ContainerStateFailed {
+		c.lock.Unlock()
+		return nil
+	}
+
+	c.state = ContainerStateClosing
+	c.transition = make(chan struct{})
+	c.lock.Unlock()
+
+	defer func() {
+		c.finishTransition(ContainerStateClosed)
+	}()
+
+	return c.close(ctx, correlationId)
+}
+
+func (c *Container) close(ctx context.Context, correlationId string) error {
 	// Skip if container wasn't opened
 	if c.References == nil {
 		return nil
@@ -292,6 +919,21 @@ func (c *Container) Close(ctx context.Context, correlationId string) error {
 	}()
 
 	c.logger.Trace(ctx, correlationId, "Stopping %s container", c.info.Name)
+	start := time.Now()
+	c.notifyContainerEvent(ctx, correlationId, refer.ContainerStopping, start, nil)
+
+	// Close the group and children before their parent, so they can still use shared components
+	var groupErr error
+	if c.group != nil {
+		groupErr = c.group.Close(ctx, correlationId)
+	}
+	children := c.GetChildren()
+	for index := len(children) - 1; index >= 0; index-- {
+		if childErr := children[index].Close(ctx, correlationId); childErr != nil {
+			c.logger.Error(ctx, correlationId, childErr, "Failed to stop child container %s",
+				children[index].Info().Name)
+		}
+	}
 
 	// Unset references for child container
 	if c.unreferenceable != nil {
@@ -300,8 +942,15 @@ func (c *Container) Close(ctx context.Context, correlationId string) error {
 
 	// Close and dereference components
 	err = c.References.Close(ctx, correlationId)
+	if groupErr != nil {
+		err = groupErr
+	}
 
+	c.notifyContainerEvent(ctx, correlationId, refer.ContainerStopped, start, err)
+	c.events.SetReferences(ctx, nil)
+	c.lock.Lock()
 	c.References = nil
+	c.lock.Unlock()
 
 	if err == nil {
 		c.logger.Info(ctx, correlationId, "Container %s stopped", c.info.Name)
@@ -311,3 +960,180 @@ func (c *Container) Close(ctx context.Context, correlationId string) error {
 
 	return err
 }
+
+// CheckHealth polls components in the container and aggregates their health.
+// Components that implement IHealthCheckable are checked by their CheckHealth method,
+// other components that implement IOpenable are checked by their IsOpen method.
+// Components are checked concurrently, each within the health check timeout.
+//	see IHealthCheckable
+//	see SetHealthCheckTimeout
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: *HealthReport the container health report.
+func (c *Container) CheckHealth(ctx context.Context, correlationId string) *HealthReport {
+	references := c.getReferences()
+	if references == nil {
+		return newNotOpenedReport()
+	}
+	return c.checkComponents(ctx, correlationId, references, false)
+}
+
+// CheckReadiness checks if the container is ready to serve requests.
+// The container is ready when it is opened, all its components are opened
+// and not being restarted after failures, and their health checks pass.
+// It is intended for readiness probes.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: *HealthReport the container readiness report.
+func (c *Container) CheckReadiness(ctx context.Context, correlationId string) *HealthReport {
+	references := c.getReferences()
+	if references == nil || c.State() != ContainerStateOpened {
+		return newNotOpenedReport()
+	}
+	return c.checkComponents(ctx, correlationId, references, true)
+}
+
+func newNotOpenedReport() *HealthReport {
+	return &HealthReport{
+		Status:     HealthStatusUnhealthy,
+		Message:    "Container is not opened",
+		Time:       time.Now(),
+		Components: make([]*ComponentHealth, 0),
+	}
+}
+
+// checkComponents checks health of components concurrently. For readiness components
+// shall also be opened by the references, so components closed to restart are not ready.
+func (c *Container) checkComponents(ctx context.Context, correlationId string,
+	references *refer.ContainerReferences, readiness bool) *HealthReport {
+
+	report := &HealthReport{
+		Status:     HealthStatusHealthy,
+		Time:       time.Now(),
+		Components: make([]*ComponentHealth, 0),
+	}
+
+	locators := references.GetAllLocators()
+	components := references.GetAll()
+	results := make([]*ComponentHealth, len(components))
+
+	var wg sync.WaitGroup
+	for index, component := range components {
+		_, checkable := component.(IHealthCheckable)
+		_, openable := component.(crun.IOpenable)
+		if !checkable && !openable {
+			continue
+		}
+
+		if readiness && openable && !references.Runner.IsComponentOpen(component) {
+			results[index] = &ComponentHealth{
+				Locator: fmt.Sprintf("%v", locators[index]),
+				Status:  HealthStatusUnhealthy,
+				Message: "Component is not opened",
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, component any) {
+			defer wg.Done()
+			results[index] = checkComponentHealth(ctx, correlationId, locators[index], component, c.healthTimeout)
+		}(index, component)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		report.Components = append(report.Components, result)
+		if result.Status != HealthStatusHealthy {
+			report.Status = HealthStatusUnhealthy
+		}
+	}
+
+	return report
+}
+
+// CheckLiveness checks if the container is alive.
+// Unlike readiness it does not poll components, because failures
+// of external dependencies are not fixed by restarting the process.
+// It is intended for liveness probes.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: *HealthReport the container liveness report.
+func (c *Container) CheckLiveness(ctx context.Context, correlationId string) *HealthReport {
+	report := &HealthReport{
+		Status: HealthStatusHealthy,
+		Time:   time.Now(),
+	}
+
+	if !c.IsOpen() {
+		report.Status = HealthStatusUnhealthy
+		report.Message = "Container is not opened"
+	}
+
+	return report
+}
+
+// checkComponentHealth checks health of a single component within the timeout.
+// A check that does not complete in time is reported as unhealthy and left running.
+func checkComponentHealth(ctx context.Context, correlationId string,
+	locator any, component any, timeout time.Duration) *ComponentHealth {
+
+	start := time.Now()
+	result := &ComponentHealth{
+		Locator: fmt.Sprintf("%v", locator),
+		Status:  HealthStatusHealthy,
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan string, 1)
+	go func() {
+		done <- getComponentHealthError(ctx, correlationId, component)
+	}()
+
+	select {
+	case message := <-done:
+		if message != "" {
+			result.Status = HealthStatusUnhealthy
+			result.Message = message
+		}
+	case <-ctx.Done():
+		result.Status = HealthStatusUnhealthy
+		if ctx.Err() == context.DeadlineExceeded && timeout > 0 {
+			result.Message = fmt.Sprintf("Health check did not complete within %v", timeout)
+		} else {
+			result.Message = "Health check was cancelled: " + ctx.Err().Error()
+		}
+	}
+
+	result.Latency = time.Since(start)
+	return result
+}
+
+// getComponentHealthError checks health of a component and returns a message when it is unhealthy.
+func getComponentHealthError(ctx context.Context, correlationId string, component any) (message string) {
+	defer func() {
+		if r := recover(); r != nil {
+			message = cconv.StringConverter.ToString(r)
+		}
+	}()
+
+	if checkable, ok := component.(IHealthCheckable); ok {
+		if err := checkable.CheckHealth(ctx, correlationId); err != nil {
+			return err.Error()
+		}
+	} else if !crun.Opener.IsOpenOne(component) {
+		return "Component is not opened"
+	}
+	return ""
+}
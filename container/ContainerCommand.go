@@ -0,0 +1,59 @@
+package container
+
+import (
+	"context"
+
+	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+)
+
+//	Built-in commands of the process container
+//	CommandRun: reads the configuration and runs the container until it is stopped (default)
+//	CommandValidate: checks the configuration without opening components
+//	CommandDescribe: lists configured components and factories that create them
+//	CommandGraph: opens the container and prints references between components
+//	CommandPrintConfig: prints the effective configuration without opening components
+//	CommandVersion: prints the container name and version
+const (
+	CommandRun         = "run"
+	CommandValidate    = "validate"
+	CommandDescribe    = "describe"
+	CommandGraph       = "graph"
+	CommandPrintConfig = "print-config"
+	CommandVersion     = "version"
+)
+
+// ContainerCommand is a subcommand of the process container given
+// as the first positional command line argument, for instance "service migrate".
+// The action gets a container with read configuration, but it is not opened,
+// so the action decides which components it needs and opens the container when required.
+//	see ProcessContainer.AddCommand
+//
+//	Example:
+//		container.AddCommand(&ContainerCommand{
+//			Name:        "migrate",
+//			Description: "migrate the database schema",
+//			Action: func(ctx context.Context, correlationId string, c *ProcessContainer, args []string) error {
+//				if err := c.Open(ctx, correlationId); err != nil {
+//					return err
+//				}
+//				defer c.Close(ctx, correlationId)
+//				...
+//			},
+//		})
+type ContainerCommand struct {
+	// Name of the command given as the first positional argument
+	Name string
+	// ArgsName is a name of the command arguments shown in help or "" when the command has no arguments
+	ArgsName string
+	// Description is shown in help
+	Description string
+	// SkipConfig calls the action without reading the container configuration
+	SkipConfig bool
+	// Action is called with the container and positional arguments that follow the command name.
+	// Its error terminates the process with ExitCodeFailure. A command without action runs the container.
+	Action func(ctx context.Context, correlationId string, container *ProcessContainer, args []string) error
+
+	// run implements a built-in command with its own termination reason
+	run func(ctx context.Context, correlationId string, path string,
+		parameters *cconfig.ConfigParams, args []string) *RunResult
+}
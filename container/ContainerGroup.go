@@ -0,0 +1,396 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	crun "github.com/pip-services3-gox/pip-services3-commons-gox/run"
+	cbuild "github.com/pip-services3-gox/pip-services3-components-gox/build"
+	"github.com/pip-services3-gox/pip-services3-components-gox/log"
+	"github.com/pip-services3-gox/pip-services3-container-gox/refer"
+)
+
+// GroupFailureMode defines how the container group reacts on failures of its members.
+type GroupFailureMode string
+
+//	Group failure modes
+//	GroupFailAll: failure of one member fails the entire group
+//	GroupIsolate: the failed member is closed while other members keep running
+const (
+	GroupFailAll GroupFailureMode = "fail_all"
+	GroupIsolate GroupFailureMode = "isolate"
+)
+
+// ContainerGroup runs several containers in one process.
+// Each member has its own configuration, name and ContextInfo.
+// Members are opened in the order they were added and closed in the reverse order.
+// Components of a member are hidden from other members unless the member exports them.
+// Exported components are available to members opened after the exporting one.
+//
+// When a member fails to open or reports a runtime failure with run.SendShutdownSignalWithErr,
+// in GroupFailAll mode the entire group fails, and in GroupIsolate mode
+// only the failed member is closed and reported as unhealthy.
+//	see ProcessContainer.SetGroup
+//
+//	Example:
+//		group := NewContainerGroup("local", GroupIsolate)
+//		group.AddFactory(NewMyComponentFactory())
+//		group.AddMemberFromFile(ctx, "123", "service1", "./config/service1.yml", nil,
+//			crefer.NewDescriptor("service1", "client", "*", "*", "1.0"))
+//		group.AddMemberFromFile(ctx, "123", "service2", "./config/service2.yml", nil)
+//
+//		container := NewProcessContainer("local", "Local development")
+//		container.SetGroup(group)
+//		container.Run(context.Background(), os.Args)
+type ContainerGroup struct {
+	lock        sync.Mutex
+	name        string
+	failureMode GroupFailureMode
+	logger      log.ILogger
+	factories   []cbuild.IFactory
+	members     []*groupMember
+	shared      *refer.FallbackReferencesDecorator
+	opened      bool
+}
+
+type groupMember struct {
+	container *Container
+	exports   []any
+	failures  crun.ContextShutdownWithErrorChan
+	stop      chan struct{}
+	err       error
+	// Components shared with other members while the member is opened
+	exported []*crefer.Reference
+}
+
+// NewContainerGroup creates a new instance of the container group.
+//	Parameters:
+//		- name string a group name.
+//		- failureMode GroupFailureMode how the group reacts on failures of its members.
+//	Returns: *ContainerGroup
+func NewContainerGroup(name string, failureMode GroupFailureMode) *ContainerGroup {
+	return &ContainerGroup{
+		name:        name,
+		failureMode: failureMode,
+		logger:      log.NewNullLogger(),
+		factories:   make([]cbuild.IFactory, 0),
+		members:     make([]*groupMember, 0),
+		shared:      refer.NewFallbackReferencesDecorator(crefer.NewEmptyReferences(), nil),
+	}
+}
+
+// Name gets the group name.
+//	Returns: string the group name.
+func (c *ContainerGroup) Name() string {
+	return c.name
+}
+
+// SetLogger sets a logger to log failures of group members.
+//	Parameters:
+//		- logger log.ILogger a logger.
+func (c *ContainerGroup) SetLogger(logger log.ILogger) {
+	c.logger = logger
+}
+
+// AddFactory adds a factory to all current and future members of the group.
+//	Parameters:
+//		- factory cbuild.IFactory a component factory to be added.
+func (c *ContainerGroup) AddFactory(factory cbuild.IFactory) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.factories = append(c.factories, factory)
+	for _, member := range c.members {
+		member.container.AddFactory(factory)
+	}
+}
+
+// AddMember adds a container to the group.
+//	Parameters:
+//		- container *Container a member container.
+//		- exports ...any locators of member components shared with other members.
+func (c *ContainerGroup) AddMember(container *Container, exports ...any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, factory := range c.factories {
+		container.AddFactory(factory)
+	}
+	container.setParentReferences(c.shared)
+
+	c.members = append(c.members, &groupMember{
+		container: container,
+		exports:   exports,
+	})
+}
+
+// AddMemberFromFile creates a member container and reads its configuration from a configuration file.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- name string a member name (accessible via ContextInfo).
+//		- path string a path to configuration file.
+//		- parameters *cconfig.ConfigParams values to parameterize the configuration or nil.
+//		- exports ...any locators of member components shared with other members.
+//	Returns: *Container, error the created member or error when configuration cannot be read.
+func (c *ContainerGroup) AddMemberFromFile(ctx context.Context, correlationId string,
+	name string, path string, parameters *cconfig.ConfigParams, exports ...any) (*Container, error) {
+
+	container := NewContainer(name, "")
+	if err := container.ReadConfigFromFile(ctx, correlationId, path, parameters); err != nil {
+		return nil, err
+	}
+
+	c.AddMember(container, exports...)
+	return container, nil
+}
+
+// GetMembers gets member containers.
+//	Returns: []*Container a list of members in the order they were added.
+func (c *ContainerGroup) GetMembers() []*Container {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	members := make([]*Container, 0, len(c.members))
+	for _, member := range c.members {
+		members = append(members, member.container)
+	}
+	return members
+}
+
+// SetParentReferences sets references to search components that are neither
+// in a member nor exported by other members, for instance components
+// of the process container that runs the group.
+//	Parameters:
+//		- references crefer.IReferences parent references or nil.
+func (c *ContainerGroup) SetParentReferences(references crefer.IReferences) {
+	c.shared.SetParent(references)
+}
+
+// IsOpen checks if the group is opened.
+//	Returns: bool true if the group has been opened and false otherwise.
+func (c *ContainerGroup) IsOpen() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.opened
+}
+
+// Open opens all members of the group.
+// In GroupFailAll mode a failure to open a member closes already opened members
+// and returns the error. In GroupIsolate mode failed members are skipped.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: error
+func (c *ContainerGroup) Open(ctx context.Context, correlationId string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.opened {
+		return nil
+	}
+
+	for _, member := range c.members {
+		member.err = nil
+		err := c.openMember(ctx, correlationId, member)
+		if err == nil {
+			continue
+		}
+
+		member.err = err
+		if c.failureMode != GroupIsolate {
+			c.closeMembers(ctx, correlationId)
+			return err
+		}
+		c.logger.Error(ctx, correlationId, err, "Member %s of group %s failed to open and was isolated",
+			member.container.Info().Name, c.name)
+	}
+
+	c.opened = true
+	return nil
+}
+
+func (c *ContainerGroup) openMember(ctx context.Context, correlationId string, member *groupMember) error {
+	// Deliver runtime failures of the member to the group
+	member.failures = make(crun.ContextShutdownWithErrorChan, 1)
+	member.stop = make(chan struct{})
+	memberCtx, _ := crun.AddErrShutdownChanToContext(ctx, member.failures)
+	go c.watchMember(ctx, correlationId, member, member.failures, member.stop)
+
+	err := member.container.Open(memberCtx, correlationId)
+	if err != nil {
+		_ = member.container.Close(ctx, correlationId)
+		close(member.stop)
+		return err
+	}
+
+	c.exportComponents(member)
+	return nil
+}
+
+func (c *ContainerGroup) watchMember(ctx context.Context, correlationId string, member *groupMember,
+	failures crun.ContextShutdownWithErrorChan, stop chan struct{}) {
+
+	select {
+	case <-stop:
+		return
+	case err := <-failures:
+		if c.failureMode != GroupIsolate {
+			crun.SendShutdownSignalWithErr(ctx, err)
+			return
+		}
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if isStopped(stop) {
+			return
+		}
+
+		c.logger.Error(ctx, correlationId, err, "Member %s of group %s failed and was isolated",
+			member.container.Info().Name, c.name)
+		member.err = err
+		c.closeMember(ctx, correlationId, member)
+	}
+}
+
+func (c *ContainerGroup) exportComponents(member *groupMember) {
+	references := member.container.getReferences()
+	if references == nil {
+		return
+	}
+
+	locators := references.GetAllLocators()
+	components := references.GetAll()
+	for _, export := range member.exports {
+		for index, component := range components {
+			reference := crefer.NewReference(locators[index], component)
+			if reference.Match(export) {
+				member.exported = append(member.exported, reference)
+				c.shared.Put(context.Background(), locators[index], component)
+			}
+		}
+	}
+}
+
+// unexportComponents removes components exported by the member from the shared references.
+// Shared references are rebuilt from exports of other members, since removing by locator
+// can remove a component of another member and components may not be comparable.
+func (c *ContainerGroup) unexportComponents(member *groupMember) {
+	if len(member.exported) == 0 {
+		return
+	}
+	member.exported = nil
+
+	shared := crefer.NewEmptyReferences()
+	for _, other := range c.members {
+		for _, reference := range other.exported {
+			shared.Put(context.Background(), reference.Locator(), reference.Component())
+		}
+	}
+	c.shared.SetNextReferences(shared)
+}
+
+// closeMember closes a member and removes its exported components.
+func (c *ContainerGroup) closeMember(ctx context.Context, correlationId string, member *groupMember) error {
+	if member.stop != nil && !isStopped(member.stop) {
+		close(member.stop)
+	}
+	c.unexportComponents(member)
+	return member.container.Close(ctx, correlationId)
+}
+
+func (c *ContainerGroup) closeMembers(ctx context.Context, correlationId string) error {
+	var closeErr error
+	for index := len(c.members) - 1; index >= 0; index-- {
+		member := c.members[index]
+		if err := c.closeMember(ctx, correlationId, member); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
+
+// Close closes all members of the group in the reverse order.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: error the first error raised by members.
+func (c *ContainerGroup) Close(ctx context.Context, correlationId string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.opened {
+		return nil
+	}
+
+	c.opened = false
+	return c.closeMembers(ctx, correlationId)
+}
+
+// CheckHealth checks health of all group members.
+// Component locators in the report are prefixed with member names.
+// Members that failed and were isolated are reported as unhealthy.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//	Returns: *HealthReport the aggregated health report.
+func (c *ContainerGroup) CheckHealth(ctx context.Context, correlationId string) *HealthReport {
+	c.lock.Lock()
+	members := append([]*groupMember{}, c.members...)
+	failures := make([]error, len(members))
+	for index, member := range members {
+		failures[index] = member.err
+	}
+	c.lock.Unlock()
+
+	report := &HealthReport{
+		Status:     HealthStatusHealthy,
+		Time:       time.Now(),
+		Components: make([]*ComponentHealth, 0),
+	}
+
+	for index, member := range members {
+		name := member.container.Info().Name
+
+		if failures[index] != nil {
+			report.Components = append(report.Components, &ComponentHealth{
+				Locator: name,
+				Status:  HealthStatusUnhealthy,
+				Message: failures[index].Error(),
+			})
+			report.Status = HealthStatusUnhealthy
+			continue
+		}
+
+		memberReport := member.container.CheckHealth(ctx, correlationId)
+		if !memberReport.IsHealthy() {
+			report.Status = HealthStatusUnhealthy
+			if memberReport.Message != "" {
+				report.Components = append(report.Components, &ComponentHealth{
+					Locator: name,
+					Status:  HealthStatusUnhealthy,
+					Message: memberReport.Message,
+				})
+			}
+		}
+		for _, componentHealth := range memberReport.Components {
+			componentHealth.Locator = fmt.Sprintf("%s/%s", name, componentHealth.Locator)
+			report.Components = append(report.Components, componentHealth)
+		}
+	}
+
+	return report
+}
+
+func isStopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,24 @@
+package container
+
+// ContainerState state of the container lifecycle.
+//	Transitions:
+//		created -> opening -> opened -> closing -> closed
+//		opening -> failed -> closing -> closed
+//		closed -> opening
+type ContainerState string
+
+//	Container states
+//	ContainerStateCreated: the container was created but not opened yet
+//	ContainerStateOpening: the container creates and opens its components
+//	ContainerStateOpened: all components were successfully opened
+//	ContainerStateClosing: the container closes its components
+//	ContainerStateClosed: the container was closed
+//	ContainerStateFailed: the container failed to open or the open was cancelled
+const (
+	ContainerStateCreated ContainerState = "created"
+	ContainerStateOpening ContainerState = "opening"
+	ContainerStateOpened  ContainerState = "opened"
+	ContainerStateClosing ContainerState = "closing"
+	ContainerStateClosed  ContainerState = "closed"
+	ContainerStateFailed  ContainerState = "failed"
+)
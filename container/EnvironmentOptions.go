@@ -0,0 +1,127 @@
+package container
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+)
+
+// EnvironmentOptions defines how environment variables and variables
+// from .env file are injected into configuration parameters.
+//
+//	Example:
+//		// APP__LOGGER__LEVEL=debug is injected as logger.level=debug,
+//		// variables without APP__ prefix are ignored
+//		options := EnvironmentOptions{
+//			Prefix:      "APP__",
+//			StripPrefix: true,
+//			Separator:   "__",
+//			LowerCase:   true,
+//		}
+type EnvironmentOptions struct {
+	// Prefix of injected variables or "" to inject all variables
+	Prefix string
+	// StripPrefix removes the prefix from names of parameters
+	StripPrefix bool
+	// Separator of nested names replaced with dots, for instance "__", or "" to keep names
+	Separator string
+	// LowerCase converts names of parameters to lower case
+	LowerCase bool
+}
+
+// DefaultParameterPrecedence gets the default precedence of parameter sources:
+// command line, environment variables, .env file and default parameters.
+//	Returns: []config.ParameterSource parameter sources from the highest precedence to the lowest.
+func DefaultParameterPrecedence() []config.ParameterSource {
+	return []config.ParameterSource{
+		config.ParameterSourceCli,
+		config.ParameterSourceEnvironment,
+		config.ParameterSourceEnvFile,
+		config.ParameterSourceDefault,
+	}
+}
+
+// GetParameterName converts a name of environment variable into a name of configuration parameter.
+//	Parameters:
+//		- name string a name of environment variable.
+//	Returns: string, bool the parameter name and false when the variable shall not be injected.
+func (c EnvironmentOptions) GetParameterName(name string) (string, bool) {
+	if !strings.HasPrefix(name, c.Prefix) {
+		return "", false
+	}
+	if c.StripPrefix {
+		name = strings.TrimPrefix(name, c.Prefix)
+	}
+	if c.Separator != "" {
+		name = strings.ReplaceAll(name, c.Separator, ".")
+	}
+	if c.LowerCase {
+		name = strings.ToLower(name)
+	}
+	return name, name != ""
+}
+
+// ReadEnvironment reads variables given as "name=value" pairs into configuration parameters.
+//	Parameters:
+//		- environ []string variables, for instance from os.Environ().
+//	Returns: *cconfig.ConfigParams parameters with injected variables.
+func (c EnvironmentOptions) ReadEnvironment(environ []string) *cconfig.ConfigParams {
+	parameters := cconfig.NewEmptyConfigParams()
+	for _, e := range environ {
+		env := strings.SplitN(e, "=", 2)
+		if len(env) < 2 {
+			continue
+		}
+		if name, ok := c.GetParameterName(env[0]); ok {
+			parameters.Put(name, env[1])
+		}
+	}
+	return parameters
+}
+
+// ReadEnvFile reads variables from .env file into configuration parameters.
+// The file contains "NAME=value" lines, optionally prefixed with "export".
+// Values can be quoted, lines that start with "#" are comments.
+//	Parameters:
+//		- path string a path to .env file.
+//	Returns: *cconfig.ConfigParams, error parameters with injected variables and FileError when the file cannot be read.
+func (c EnvironmentOptions) ReadEnvFile(path string) (*cconfig.ConfigParams, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, cerr.NewFileError(
+			"", "READ_FAILED", "Failed reading environment file "+path+": "+err.Error(),
+		).WithCause(err).WithDetails("path", path)
+	}
+	defer file.Close()
+
+	environ := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		env := strings.SplitN(line, "=", 2)
+		if len(env) < 2 {
+			continue
+		}
+		name, value := strings.TrimSpace(env[0]), strings.TrimSpace(env[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		environ = append(environ, name+"="+value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, cerr.NewFileError(
+			"", "READ_FAILED", "Failed reading environment file "+path+": "+err.Error(),
+		).WithCause(err).WithDetails("path", path)
+	}
+
+	return c.ReadEnvironment(environ), nil
+}
@@ -0,0 +1,42 @@
+package container
+
+import "time"
+
+// HealthStatus status of a component or the entire container.
+type HealthStatus string
+
+//	Health statuses
+//	HealthStatusHealthy: component or container works normally
+//	HealthStatusUnhealthy: component or container does not work
+const (
+	HealthStatusHealthy   HealthStatus = "healthy"
+	HealthStatusUnhealthy HealthStatus = "unhealthy"
+)
+
+// DefaultHealthCheckTimeout maximum time to check health of a single component.
+// Components that do not respond in time are reported as unhealthy.
+//	see Container.SetHealthCheckTimeout
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// ComponentHealth health status of a single component in the container.
+type ComponentHealth struct {
+	Locator string        `json:"locator"`
+	Status  HealthStatus  `json:"status"`
+	Message string        `json:"message,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+// HealthReport aggregated health status of the container and its components.
+// The container is healthy only when all checked components are healthy.
+type HealthReport struct {
+	Status     HealthStatus       `json:"status"`
+	Message    string             `json:"message,omitempty"`
+	Time       time.Time          `json:"time"`
+	Components []*ComponentHealth `json:"components,omitempty"`
+}
+
+// IsHealthy checks if the container is healthy.
+//	Returns: bool true if the container is healthy and false otherwise.
+func (c *HealthReport) IsHealthy() bool {
+	return c.Status == HealthStatusHealthy
+}
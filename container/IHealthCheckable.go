@@ -0,0 +1,24 @@
+package container
+
+import "context"
+
+// IHealthCheckable interface for components that can check their own health.
+// Components that do not implement this interface are checked
+// by their IsOpen method when they implement IOpenable interface.
+//	see Container.CheckHealth
+//	Example:
+//		type MyPersistence struct {
+//			client *mongo.Client
+//		}
+//
+//		func (c *MyPersistence) CheckHealth(ctx context.Context, correlationId string) error {
+//			return c.client.Ping(ctx, nil)
+//		}
+type IHealthCheckable interface {
+	// CheckHealth checks health of the component.
+	//	Parameters:
+	//		- ctx context.Context
+	//		- correlationId string transaction id to trace execution through call chain.
+	//	Returns: error nil when the component is healthy or error that describes the problem.
+	CheckHealth(ctx context.Context, correlationId string) error
+}
@@ -8,37 +8,101 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 	crun "github.com/pip-services3-gox/pip-services3-commons-gox/run"
 	"github.com/pip-services3-gox/pip-services3-components-gox/log"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+	"github.com/pip-services3-gox/pip-services3-container-gox/refer"
 )
 
 // ProcessContainer inversion of control (IoC) container that runs as a system process.
 // It processes command line arguments and handles unhandled exceptions and Ctrl-C signal
-// to gracefully shutdown the container.
+// to gracefully shutdown the container. SIGHUP signal reloads the container configuration
+// from the configuration file without restarting the process.
+// On shutdown the container waits for an optional pre-stop delay, so load balancers
+// can deregister the instance, and then closes components within the grace period.
+// A second SIGINT or SIGTERM received during shutdown forces the process to exit
+// immediately with ExitCodeForced.
+// Several containers can run in the same process as a ContainerGroup, see SetGroup.
+//
+//	Configuration parameters (in the context info section):
+//		grace_period: maximum time in milliseconds to stop the container (default: no limit)
+//		pre_stop_delay: delay in milliseconds before the container is stopped (default: 0)
+//
+//	Commands (the first positional argument):
+//		run runs the container until it is stopped (default)
+//		validate checks the configuration and exits without opening components
+//		describe lists configured components and factories that create them
+//		graph [dot|mermaid] opens the container, prints references between components and exits
+//		print-config [yaml|json] prints the effective configuration and exits without opening components
+//		version prints the container name and version
+//	Applications can add their own commands with AddCommand, see ContainerCommand.
 //
 //	Command line arguments:
-//		--config / -c path to JSON or YAML file with container configuration (default: "./config/config.yml")
+//		--config / -c path to JSON, YAML, TOML or .properties file with container configuration (default: "./config/config.yml")
 //		--param / --params / -p value(s) to parameterize the container configuration
+//		--profile comma-separated configuration profiles (default: PROFILE environment variable)
+//		--env-file path to .env file with variables to parameterize the configuration
+//		--strict fails when configuration placeholders have no values, see Container.SetStrict
+//		--validate / --dry-run same as validate command
+//		--print-config [yaml|json] same as print-config command
+//		--graph [dot|mermaid] same as graph command
 //		--help / -h prints the container usage help
+//	Values can be given as --name value, --name=value or -n=value. Unknown flags are rejected.
+//	Applications can register their own flags with AddFlag, see CommandLine.
+//
+//	Parameters of the configuration come from these sources, from the highest precedence to the lowest:
+//		command line (--param), environment variables, .env file (--env-file) and defaults (SetDefaultParameters).
+//	The order can be changed by SetParameterPrecedence, and SetEnvironmentOptions
+//	filters environment variables by a prefix and maps their names to nested parameters.
 //	see Container
 //
 //	Example:
 //		container = NewEmptyProcessContainer()
 //		container.Container.AddFactory(NewMyComponentFactory())
-//		container.Run(context.Background(), os.Environ())
+//		container.Run(context.Background(), os.Args)
+//
+//		// Or run without exiting the process
+//		result := container.RunWithResult(ctx, os.Args)
+//		fmt.Println(result.Reason, result.ExitCode)
+//
+//		// Check the configuration from the command line
+//		// > service validate -c ./config/config.yml
 type ProcessContainer struct {
 	*Container
 	configPath            string
 	feedbackChan          crun.ContextShutdownChan
 	feedbackWithErrorChan crun.ContextShutdownWithErrorChan
+	gracePeriod           time.Duration
+	preStopDelay          time.Duration
+	defaultParameters     *cconfig.ConfigParams
+	envOptions            EnvironmentOptions
+	envFilePath           string
+	precedence            []config.ParameterSource
+	commandLine           *CommandLine
+	commands              []*ContainerCommand
+	version               string
 }
 
 const DefaultConfigFilePath = "./config/config.yml"
 
+//	Process exit codes
+//	ExitCodeSuccess: the container was gracefully stopped
+//	ExitCodeFailure: the container failed to start or was terminated by an error
+//	ExitCodeForced: a second stop signal forced the process to exit during shutdown
+//	ExitCodeShutdownTimeout: the container did not stop within the shutdown grace period
+const (
+	ExitCodeSuccess         = 0
+	ExitCodeFailure         = 1
+	ExitCodeForced          = 2
+	ExitCodeShutdownTimeout = 3
+)
+
 // NewEmptyProcessContainer creates a new empty instance of the container.
 //	Returns: ProcessContainer
 func NewEmptyProcessContainer() *ProcessContainer {
@@ -47,7 +111,10 @@ func NewEmptyProcessContainer() *ProcessContainer {
 		configPath:            DefaultConfigFilePath,
 		feedbackChan:          make(crun.ContextShutdownChan),
 		feedbackWithErrorChan: make(crun.ContextShutdownWithErrorChan),
+		commandLine:           NewCommandLine(),
 	}
+	c.addDefaultFlags()
+	c.addDefaultCommands()
 	c.SetLogger(log.NewConsoleLogger())
 	return c
 }
@@ -63,7 +130,10 @@ func NewProcessContainer(name string, description string) *ProcessContainer {
 		configPath:            DefaultConfigFilePath,
 		feedbackChan:          make(crun.ContextShutdownChan),
 		feedbackWithErrorChan: make(crun.ContextShutdownWithErrorChan),
+		commandLine:           NewCommandLine(),
 	}
+	c.addDefaultFlags()
+	c.addDefaultCommands()
 	c.SetLogger(log.NewConsoleLogger())
 	return c
 }
@@ -83,7 +153,10 @@ func InheritProcessContainer(name string, description string,
 		configPath:            DefaultConfigFilePath,
 		feedbackChan:          make(crun.ContextShutdownChan),
 		feedbackWithErrorChan: make(crun.ContextShutdownWithErrorChan),
+		commandLine:           NewCommandLine(),
 	}
+	c.addDefaultFlags()
+	c.addDefaultCommands()
 	c.SetLogger(log.NewConsoleLogger())
 	return c
 }
@@ -91,99 +164,683 @@ func InheritProcessContainer(name string, description string,
 // SetConfigPath set path for configuration file
 func (c *ProcessContainer) SetConfigPath(configPath string) {
 	c.configPath = configPath
+	c.commandLine.GetFlag("config").Default = configPath
 }
 
-func (c *ProcessContainer) getConfigPath(args []string) string {
-	for index, arg := range args {
-		nextArg := ""
-		if index < len(args)-1 {
-			nextArg = args[index+1]
-			if strings.HasPrefix(nextArg, "-") {
-				nextArg = ""
-			}
+// SetDefaultParameters sets default values to parameterize the container configuration.
+// Parameters from other sources take precedence over the defaults.
+//	see SetParameterPrecedence
+//	Parameters:
+//		- parameters *cconfig.ConfigParams default parameters or nil.
+func (c *ProcessContainer) SetDefaultParameters(parameters *cconfig.ConfigParams) {
+	c.defaultParameters = parameters
+}
+
+// SetEnvironmentOptions sets options to inject environment variables and variables
+// from .env file into configuration parameters. By default all environment variables
+// are injected with unchanged names.
+//	Parameters:
+//		- options EnvironmentOptions options to filter and rename variables.
+func (c *ProcessContainer) SetEnvironmentOptions(options EnvironmentOptions) {
+	c.envOptions = options
+}
+
+// SetEnvFile sets a path to .env file with variables to parameterize the container configuration.
+// The path can be overridden by --env-file command line argument.
+//	Parameters:
+//		- path string a path to .env file or "" to skip it.
+func (c *ProcessContainer) SetEnvFile(path string) {
+	c.envFilePath = path
+}
+
+// SetParameterPrecedence sets precedence of parameter sources.
+// Values from sources with higher precedence override values from sources with lower precedence,
+// sources missing in the list are not used.
+//	see DefaultParameterPrecedence
+//	Parameters:
+//		- sources ...config.ParameterSource parameter sources from the highest precedence to the lowest.
+func (c *ProcessContainer) SetParameterPrecedence(sources ...config.ParameterSource) {
+	c.precedence = sources
+}
+
+// SetGroup sets a group of containers to run in this process.
+// The group is opened after components of the process container and closed before them
+// in the same Open and Close calls, so a stop signal received while opening closes the group too.
+// Components of the process container are available to all group members.
+//	see ContainerGroup
+//	Parameters:
+//		- group *ContainerGroup a group of containers or nil.
+func (c *ProcessContainer) SetGroup(group *ContainerGroup) {
+	c.group = group
+}
+
+// SetShutdownOptions sets options to gracefully shutdown the process.
+// Options defined in the container configuration take precedence over these values.
+//	Parameters:
+//		- gracePeriod time.Duration maximum time to stop the container or 0 to wait without limit.
+//		- preStopDelay time.Duration delay before the container is stopped,
+//			so load balancers can deregister the instance, or 0 for no delay.
+func (c *ProcessContainer) SetShutdownOptions(gracePeriod time.Duration, preStopDelay time.Duration) {
+	c.gracePeriod = gracePeriod
+	c.preStopDelay = preStopDelay
+}
+
+func (c *ProcessContainer) getShutdownOptions() (gracePeriod time.Duration, preStopDelay time.Duration) {
+	gracePeriod, preStopDelay = c.config.GetShutdownOptions()
+	if gracePeriod <= 0 {
+		gracePeriod = c.gracePeriod
+	}
+	if preStopDelay <= 0 {
+		preStopDelay = c.preStopDelay
+	}
+	return gracePeriod, preStopDelay
+}
+
+// shutdown gracefully stops the container within the grace period.
+// A second stop signal received during shutdown forces the process to exit immediately.
+//	Returns: int, error the process exit code and an error when the container was not stopped.
+func (c *ProcessContainer) shutdown(ctx context.Context, correlationId string,
+	signals <-chan os.Signal) (int, error) {
+
+	gracePeriod, preStopDelay := c.getShutdownOptions()
+	deadline := time.Now().Add(gracePeriod)
+	timeLeft := func() string {
+		if gracePeriod <= 0 {
+			return "no grace period"
 		}
+		return fmt.Sprintf("%v of grace period left", time.Until(deadline).Round(time.Millisecond))
+	}
 
-		if arg == "--config" || arg == "-c" {
-			return nextArg
+	var timeout <-chan time.Time
+	if gracePeriod > 0 {
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	if preStopDelay > 0 {
+		c.Logger().Info(ctx, correlationId, "Waiting %v before stop to deregister the instance (%s)",
+			preStopDelay, timeLeft())
+
+		delay := time.NewTimer(preStopDelay)
+		defer delay.Stop()
+
+	preStop:
+		for {
+			select {
+			case <-delay.C:
+				break preStop
+			case <-timeout:
+				return c.shutdownTimeout(ctx, correlationId, gracePeriod)
+			case sig := <-signals:
+				if sig == syscall.SIGHUP {
+					continue
+				}
+				return c.forceShutdown(ctx, correlationId, sig)
+			}
 		}
 	}
 
-	return c.configPath
-}
+	c.Logger().Info(ctx, correlationId, "Stopping container (%s)", timeLeft())
 
-func (c *ProcessContainer) getParameters(args []string) *cconfig.ConfigParams {
-	line := ""
+	done := goWithRecover(func() error {
+		return c.Close(ctx, correlationId)
+	})
 
-	for index := 0; index < len(args); index++ {
-		arg := args[index]
-		nextArg := ""
-		if index < len(args)-1 {
-			nextArg = args[index+1]
-			if strings.HasPrefix(nextArg, "-") {
-				nextArg = ""
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				c.Logger().Error(ctx, correlationId, err, "Container stopped with errors (%s)", timeLeft())
+				return ExitCodeFailure, err
 			}
+			c.Logger().Info(ctx, correlationId, "Container stopped (%s)", timeLeft())
+			return ExitCodeSuccess, nil
+		case <-timeout:
+			return c.shutdownTimeout(ctx, correlationId, gracePeriod)
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				continue
+			}
+			return c.forceShutdown(ctx, correlationId, sig)
 		}
+	}
+}
+
+func (c *ProcessContainer) shutdownTimeout(ctx context.Context, correlationId string,
+	gracePeriod time.Duration) (int, error) {
+
+	err := cerr.NewInvalidStateError(
+		correlationId, "SHUTDOWN_TIMEOUT",
+		fmt.Sprintf("Container did not stop within grace period of %v", gracePeriod),
+	)
+	c.Logger().Error(ctx, correlationId, err, "Shutdown grace period exceeded")
+	return ExitCodeShutdownTimeout, err
+}
+
+func (c *ProcessContainer) forceShutdown(ctx context.Context, correlationId string,
+	sig os.Signal) (int, error) {
+
+	err := cerr.NewInvalidStateError(
+		correlationId, "SHUTDOWN_FORCED",
+		fmt.Sprintf("Shutdown was forced by %v signal", sig),
+	)
+	c.Logger().Warn(ctx, correlationId, "Received %v signal during shutdown, forcing exit", sig)
+	return ExitCodeForced, err
+}
+
+// addDefaultFlags registers flags of the process container.
+func (c *ProcessContainer) addDefaultFlags() {
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "config", Aliases: []string{"c"}, ValueName: "path", Default: c.configPath,
+		Description: "path to JSON, YAML, TOML or .properties file with container configuration",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "param", Aliases: []string{"p", "params"}, ValueName: "name=value", Repeated: true,
+		Description: "value(s) to parameterize the container configuration",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "profile", Aliases: []string{"profiles"}, ValueName: "profiles",
+		Description: "comma-separated configuration profiles (default: PROFILE environment variable)",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "env-file", ValueName: "path",
+		Description: "path to .env file with variables to parameterize the configuration",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name:        "strict",
+		Description: "fail when configuration placeholders have no values",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "validate", Aliases: []string{"dry-run"},
+		Description: "check the configuration and exit without opening components",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "print-config", ValueName: "format", OptionalValue: true, Default: string(config.ConfigFormatYaml),
+		Values:      []string{string(config.ConfigFormatYaml), string(config.ConfigFormatJson)},
+		Description: "print the effective configuration and exit without opening components",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "graph", ValueName: "format", OptionalValue: true, Default: string(refer.GraphFormatDot),
+		Values:      []string{string(refer.GraphFormatDot), string(refer.GraphFormatMermaid)},
+		Description: "open the container, print references between components and exit",
+	})
+	c.commandLine.AddFlag(&CommandLineFlag{
+		Name: "help", Aliases: []string{"h"},
+		Description: "print the container usage help",
+	})
+}
+
+// CommandLine gets the command line of the container. Flags registered in it
+// are accepted by Run and their values are available after the arguments are parsed.
+//	Returns: *CommandLine the command line.
+func (c *ProcessContainer) CommandLine() *CommandLine {
+	return c.commandLine
+}
 
-		if nextArg != "" {
-			if arg == "--param" || arg == "--params" || arg == "-p" {
-				if line != "" {
-					line = line + ";"
+// AddFlag registers an application flag accepted by Run and shown in the usage help.
+// The flag value can be read with CommandLine().GetValue after the container is started.
+//	see CommandLine
+//	Parameters:
+//		- flag *CommandLineFlag a flag to register.
+func (c *ProcessContainer) AddFlag(flag *CommandLineFlag) {
+	c.commandLine.AddFlag(flag)
+}
+
+// addDefaultCommands registers built-in commands of the process container.
+func (c *ProcessContainer) addDefaultCommands() {
+	c.commands = []*ContainerCommand{
+		{
+			Name:        CommandRun,
+			Description: "run the container until it is stopped (default)",
+		},
+		{
+			Name:        CommandValidate,
+			Description: "check the configuration and exit without opening components",
+			run: func(ctx context.Context, correlationId string, path string,
+				parameters *cconfig.ConfigParams, args []string) *RunResult {
+				return c.validate(ctx, correlationId, path, parameters)
+			},
+		},
+		{
+			Name:        CommandDescribe,
+			Description: "list configured components and factories that create them",
+			run: func(ctx context.Context, correlationId string, path string,
+				parameters *cconfig.ConfigParams, args []string) *RunResult {
+				return c.describe(ctx, correlationId, path, parameters)
+			},
+		},
+		{
+			Name:        CommandGraph,
+			ArgsName:    "[" + string(refer.GraphFormatDot) + "|" + string(refer.GraphFormatMermaid) + "]",
+			Description: "open the container, print references between components and exit",
+			run: func(ctx context.Context, correlationId string, path string,
+				parameters *cconfig.ConfigParams, args []string) *RunResult {
+				format := refer.GraphFormat(c.commandLine.GetValue("graph"))
+				if len(args) > 0 {
+					format = refer.GraphFormat(args[0])
 				}
-				line = line + nextArg
-				index++
-			}
+				return c.printGraph(ctx, correlationId, path, parameters, format)
+			},
+		},
+		{
+			Name:        CommandPrintConfig,
+			ArgsName:    "[" + string(config.ConfigFormatYaml) + "|" + string(config.ConfigFormatJson) + "]",
+			Description: "print the effective configuration and exit without opening components",
+			run: func(ctx context.Context, correlationId string, path string,
+				parameters *cconfig.ConfigParams, args []string) *RunResult {
+				format := config.ConfigFormat(c.commandLine.GetValue("print-config"))
+				if len(args) > 0 {
+					format = config.ConfigFormat(args[0])
+				}
+				return c.printConfig(ctx, correlationId, path, parameters, format)
+			},
+		},
+		{
+			Name:        CommandVersion,
+			Description: "print the container name and version",
+			SkipConfig:  true,
+			run: func(ctx context.Context, correlationId string, path string,
+				parameters *cconfig.ConfigParams, args []string) *RunResult {
+				return c.printVersion()
+			},
+		},
+	}
+}
+
+// AddCommand registers an application command. A command with the same name,
+// including a built-in one, is replaced.
+//	see ContainerCommand
+//	Parameters:
+//		- command *ContainerCommand a command to register.
+func (c *ProcessContainer) AddCommand(command *ContainerCommand) {
+	for index, existing := range c.commands {
+		if existing.Name == command.Name {
+			c.commands[index] = command
+			return
+		}
+	}
+	c.commands = append(c.commands, command)
+}
+
+// GetCommand gets a registered command by its name.
+//	Parameters:
+//		- name string a command name.
+//	Returns: *ContainerCommand the found command or nil.
+func (c *ProcessContainer) GetCommand(name string) *ContainerCommand {
+	for _, command := range c.commands {
+		if command.Name == name {
+			return command
 		}
 	}
+	return nil
+}
 
-	parameters := cconfig.NewConfigParamsFromString(line)
+// GetCommands gets all registered commands.
+//	Returns: []*ContainerCommand the commands in the order they were registered.
+func (c *ProcessContainer) GetCommands() []*ContainerCommand {
+	return append([]*ContainerCommand{}, c.commands...)
+}
 
-	for _, e := range os.Environ() {
-		if env := strings.Split(e, "="); len(env) == 2 {
-			parameters.SetAsObject(env[0], env[1])
-		} else {
-			parameters.SetAsObject(env[0], strings.Join(env[1:], "="))
+// SetVersion sets the container version printed by the version command.
+//	Parameters:
+//		- version string a version, for instance "1.2.0".
+func (c *ProcessContainer) SetVersion(version string) {
+	c.version = version
+}
+
+// getCommand selects the command given by the first positional argument.
+// Without the argument the command is selected by the legacy flags or defaults to run.
+//	Returns: *ContainerCommand, []string, error the command, its arguments
+//		and BadRequestError when the command is unknown.
+func (c *ProcessContainer) getCommand() (*ContainerCommand, []string, error) {
+	args := c.commandLine.GetArgs()
+	if len(args) > 0 {
+		command := c.GetCommand(args[0])
+		if command == nil {
+			return nil, nil, cerr.NewBadRequestError(
+				"", "UNKNOWN_COMMAND", "Unknown command "+args[0],
+			).WithDetails("command", args[0])
 		}
+		return command, args[1:], nil
 	}
 
-	return parameters
+	name := CommandRun
+	switch {
+	case c.commandLine.IsSet("validate"):
+		name = CommandValidate
+	case c.commandLine.IsSet("print-config"):
+		name = CommandPrintConfig
+	case c.commandLine.IsSet("graph"):
+		name = CommandGraph
+	}
+	return c.GetCommand(name), args, nil
 }
 
-func (c *ProcessContainer) showHelp(args []string) bool {
-	for _, arg := range args {
-		if arg == "--help" || arg == "-h" {
-			return true
+func (c *ProcessContainer) getConfigPath() string {
+	return c.commandLine.GetValue("config")
+}
+
+func (c *ProcessContainer) getProfiles() []string {
+	line := os.Getenv("PROFILE")
+	if c.commandLine.IsSet("profile") {
+		line = c.commandLine.GetValue("profile")
+	}
+
+	profiles := make([]string, 0)
+	for _, profile := range strings.Split(line, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
 		}
 	}
-	return false
+	return profiles
+}
+
+func (c *ProcessContainer) getParameters() (*cconfig.ConfigParams, error) {
+	line := strings.Join(c.commandLine.GetValues("param"), ";")
+
+	sourceParameters := map[config.ParameterSource]*cconfig.ConfigParams{
+		config.ParameterSourceCli:         cconfig.NewConfigParamsFromString(line),
+		config.ParameterSourceEnvironment: c.envOptions.ReadEnvironment(os.Environ()),
+		config.ParameterSourceDefault:     c.defaultParameters,
+	}
+	if envFilePath := c.getEnvFilePath(); envFilePath != "" {
+		envFileParameters, err := c.envOptions.ReadEnvFile(envFilePath)
+		if err != nil {
+			return nil, err
+		}
+		sourceParameters[config.ParameterSourceEnvFile] = envFileParameters
+	}
+
+	precedence := c.precedence
+	if precedence == nil {
+		precedence = DefaultParameterPrecedence()
+	}
+
+	parameters := cconfig.NewEmptyConfigParams()
+	// Remember sources of parameters to print the effective configuration
+	sources := make(map[string]config.ParameterSource)
+
+	// Apply sources from the lowest precedence, so higher ones override them
+	for index := len(precedence) - 1; index >= 0; index-- {
+		source := precedence[index]
+		values := sourceParameters[source]
+		if values == nil {
+			continue
+		}
+		for _, key := range values.Keys() {
+			parameters.Put(key, values.GetAsString(key))
+			sources[key] = source
+		}
+	}
+
+	c.parameterSources = sources
+	return parameters, nil
+}
+
+func (c *ProcessContainer) getEnvFilePath() string {
+	if c.commandLine.IsSet("env-file") {
+		return c.commandLine.GetValue("env-file")
+	}
+	return c.envFilePath
 }
 
 func (c *ProcessContainer) printHelp() {
 	fmt.Println("Pip.Services process container - http://www.github.com/pip-services/pip-services")
-	fmt.Println("run [-h] [-c <config file>] [-p <param>=<value>]*")
+	fmt.Println("Usage: run [command] [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Print(c.commandsUsage())
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Print(c.commandLine.Usage())
+}
+
+func (c *ProcessContainer) commandsUsage() string {
+	names := make([]string, len(c.commands))
+	width := 0
+	for index, command := range c.commands {
+		names[index] = strings.TrimSpace(command.Name + " " + command.ArgsName)
+		if len(names[index]) > width {
+			width = len(names[index])
+		}
+	}
+
+	builder := strings.Builder{}
+	for index, command := range c.commands {
+		builder.WriteString(fmt.Sprintf("  %-*s  %s\n", width, names[index], command.Description))
+	}
+	return builder.String()
+}
+
+// runCommand calls a command other than run. Stop signals cancel the command context.
+func (c *ProcessContainer) runCommand(ctx context.Context, correlationId string, command *ContainerCommand,
+	args []string, path string, parameters *cconfig.ConfigParams, signals <-chan os.Signal) *RunResult {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-stop:
+		}
+	}()
+
+	if command.Action == nil {
+		return command.run(ctx, correlationId, path, parameters, args)
+	}
+
+	if !command.SkipConfig {
+		if err := c.ReadConfigFromFile(ctx, correlationId, path, parameters); err != nil {
+			c.Logger().Error(ctx, correlationId, err, "Failed to read configuration")
+			return NewRunResult(ExitCodeFailure, TerminationCommand, err)
+		}
+	}
+
+	if err := command.Action(ctx, correlationId, c, args); err != nil {
+		c.Logger().Error(ctx, correlationId, err, "Command %s failed", command.Name)
+		return NewRunResult(ExitCodeFailure, TerminationCommand, err)
+	}
+	return NewRunResult(ExitCodeSuccess, TerminationCommand, nil)
+}
+
+// printVersion prints the container name and version.
+func (c *ProcessContainer) printVersion() *RunResult {
+	version := c.version
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Printf("%s %s\n", c.Info().Name, version)
+	return NewRunResult(ExitCodeSuccess, TerminationCommand, nil)
+}
+
+// describe reads the configuration and prints configured components
+// and factories that create them without creating components.
+func (c *ProcessContainer) describe(ctx context.Context, correlationId string,
+	path string, parameters *cconfig.ConfigParams) *RunResult {
+
+	err := c.ReadConfigFromFile(ctx, correlationId, path, parameters)
+	var descriptions []*ComponentDescription
+	if err == nil {
+		descriptions, err = c.Describe(ctx, correlationId)
+	}
+	if err != nil {
+		c.Logger().Error(ctx, correlationId, err, "Failed to describe components")
+		return NewRunResult(ExitCodeFailure, TerminationCommand, err)
+	}
+
+	fmt.Printf("Container %s has %d component(s) in %s:\n", c.Info().Name, len(descriptions), path)
+	for _, description := range descriptions {
+		fmt.Printf("  - %s\n", description.String())
+	}
+	return NewRunResult(ExitCodeSuccess, TerminationCommand, nil)
+}
+
+// printConfig reads the configuration and prints it without opening components.
+func (c *ProcessContainer) printConfig(ctx context.Context, correlationId string,
+	path string, parameters *cconfig.ConfigParams, format config.ConfigFormat) *RunResult {
+
+	err := c.ReadConfigFromFile(ctx, correlationId, path, parameters)
+	if err == nil {
+		err = c.WriteConfig(ctx, correlationId, os.Stdout, format)
+	}
+	if err != nil {
+		c.Logger().Error(ctx, correlationId, err, "Failed to print configuration")
+		return NewRunResult(ExitCodeFailure, TerminationPrintConfig, err)
+	}
+	return NewRunResult(ExitCodeSuccess, TerminationPrintConfig, nil)
+}
+
+// validate reads the configuration and checks that all components can be created.
+// Components are not created or opened. All found problems are printed at once.
+func (c *ProcessContainer) validate(ctx context.Context, correlationId string,
+	path string, parameters *cconfig.ConfigParams) *RunResult {
+
+	var errs []error
+	if err := c.ReadConfigFromFile(ctx, correlationId, path, parameters); err != nil {
+		errs = []error{err}
+	} else {
+		errs = c.Validate(ctx, correlationId)
+	}
+
+	if len(errs) == 0 {
+		fmt.Printf("Configuration %s is valid\n", path)
+		return NewRunResult(ExitCodeSuccess, TerminationValidate, nil)
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		// Expand problems combined by the configuration reader
+		if appErr, ok := err.(*cerr.ApplicationError); ok {
+			if details, ok := appErr.Details["errors"].([]string); ok {
+				messages = append(messages, details...)
+				continue
+			}
+		}
+		messages = append(messages, err.Error())
+	}
+
+	fmt.Printf("Configuration %s has %d error(s):\n", path, len(messages))
+	for _, message := range messages {
+		fmt.Printf("  - %s\n", message)
+	}
+	return NewRunResult(ExitCodeFailure, TerminationValidate, config.CombineConfigErrors(correlationId, errs))
+}
+
+// printGraph opens the container to record references between components,
+// prints them and closes the container. The graph is printed even when
+// the container fails to open, so missing references can be found.
+func (c *ProcessContainer) printGraph(ctx context.Context, correlationId string,
+	path string, parameters *cconfig.ConfigParams, format refer.GraphFormat) *RunResult {
+
+	err := c.ReadConfigFromFile(ctx, correlationId, path, parameters)
+	if err != nil {
+		c.Logger().Error(ctx, correlationId, err, "Failed to read configuration")
+		return NewRunResult(ExitCodeFailure, TerminationGraph, err)
+	}
+
+	err = c.Open(ctx, correlationId)
+	if writeErr := c.WriteGraph(os.Stdout, format); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	if closeErr := c.Close(ctx, correlationId); closeErr != nil && err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		c.Logger().Error(ctx, correlationId, err, "Failed to print reference graph")
+		return NewRunResult(ExitCodeFailure, TerminationGraph, err)
+	}
+	return NewRunResult(ExitCodeSuccess, TerminationGraph, nil)
+}
+
+// goWithRecover calls the action in a goroutine and sends its result
+// or a recovered panic to the returned channel.
+func goWithRecover(action func() error) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					msg := cconv.StringConverter.ToString(r)
+					err = errors.New(msg)
+				}
+				done <- err
+			}
+		}()
+		done <- action()
+	}()
+	return done
 }
 
 // Run the container by instantiating and running components inside the container.
 // It reads the container configuration, creates, configures, references
 // and opens components. On process exit it closes, unreferences and destroys
-// components to gracefully shutdown.
+// components to gracefully shutdown. The process exits with the code
+// returned by RunWithResult.
+//	see RunWithResult
 //	Parameters:
 //		- ctx context.Context
 //		- args []string command line arguments
 func (c *ProcessContainer) Run(ctx context.Context, args []string) {
-	if c.showHelp(args) {
+	result := c.RunWithResult(ctx, args)
+	os.Exit(result.ExitCode)
+}
+
+// RunWithResult runs the container like Run, but returns the result instead of exiting the process.
+// It handles stop signals and shutdown requests from components, and gracefully
+// shuts down the container when the parent context is cancelled.
+//	Parameters:
+//		- ctx context.Context the parent context. Its cancellation stops the container.
+//		- args []string command line arguments
+//	Returns: *RunResult the exit code, termination reason and error.
+func (c *ProcessContainer) RunWithResult(ctx context.Context, args []string) (result *RunResult) {
+	// Skip the program name when os.Args are given
+	if len(args) > 0 && len(os.Args) > 0 && args[0] == os.Args[0] {
+		args = args[1:]
+	}
+
+	err := c.commandLine.Parse(args)
+	var command *ContainerCommand
+	var commandArgs []string
+	if err == nil {
+		command, commandArgs, err = c.getCommand()
+	}
+	if err != nil {
+		fmt.Println(err.Error())
 		c.printHelp()
-		os.Exit(0)
-		return
+		return NewRunResult(ExitCodeFailure, TerminationInvalidArguments, err)
+	}
+	if c.commandLine.IsSet("help") {
+		c.printHelp()
+		return NewRunResult(ExitCodeSuccess, TerminationHelp, nil)
 	}
 
+	parentCtx := ctx
 	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	ctx, _ = crun.AddShutdownChanToContext(ctx, c.feedbackChan)
 	ctx, _ = crun.AddErrShutdownChanToContext(ctx, c.feedbackWithErrorChan)
+	// Components shall be able to close after the parent context is cancelled
+	closeCtx := newDetachedContext(ctx)
 
 	correlationId := c.Info().Name
-	path := c.getConfigPath(args)
-	parameters := c.getParameters(args)
+	path := c.getConfigPath()
+	parameters, err := c.getParameters()
+	if err != nil {
+		c.Logger().Fatal(ctx, correlationId, err, "Process is terminated")
+		return NewRunResult(ExitCodeFailure, TerminationStartFailed, err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGABRT)
+	defer signal.Stop(signals)
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -192,54 +849,120 @@ func (c *ProcessContainer) Run(ctx context.Context, args []string) {
 				msg := cconv.StringConverter.ToString(r)
 				err = errors.New(msg)
 			}
-			_ = c.Close(ctx, correlationId)
-			cancel()
+			_ = c.Close(closeCtx, correlationId)
 			c.Logger().Fatal(ctx, correlationId, err, "Process is terminated")
-			os.Exit(1)
+			result = NewRunResult(ExitCodeFailure, TerminationPanic, err)
 		}
 	}()
 
-	err := c.ReadConfigFromFile(ctx, correlationId, path, parameters)
+	if profiles := c.getProfiles(); len(profiles) > 0 {
+		c.SetProfiles(profiles...)
+	}
+	if c.commandLine.IsSet("strict") {
+		c.SetStrict(true)
+	}
+	if command.Action != nil || command.run != nil {
+		return c.runCommand(ctx, correlationId, command, commandArgs, path, parameters, signals)
+	}
+
+	err = c.ReadConfigFromFile(ctx, correlationId, path, parameters)
 	if err != nil {
 		c.Logger().Fatal(ctx, correlationId, err, "Process is terminated")
-		os.Exit(1)
-		return
+		return NewRunResult(ExitCodeFailure, TerminationStartFailed, err)
 	}
 
 	c.Logger().Info(ctx, correlationId, "Press Control-C to stop the microservice...")
 
-	err = c.Open(ctx, correlationId)
+	// Open in background, so stop signals can cancel it
+	opened := goWithRecover(func() error {
+		return c.Open(ctx, correlationId)
+	})
+
+	for opening := true; opening; {
+		select {
+		case err = <-opened:
+			opening = false
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				continue
+			}
+			c.Logger().Info(ctx, correlationId, "Received %v signal while starting, stopping container", sig)
+			exitCode, err := c.shutdown(closeCtx, correlationId, signals)
+			return NewRunResult(exitCode, TerminationSignal, err)
+		case <-parentCtx.Done():
+			c.Logger().Info(ctx, correlationId, "Context was cancelled while starting, stopping container")
+			exitCode, err := c.shutdown(closeCtx, correlationId, signals)
+			return NewRunResult(exitCode, TerminationCancelled, err)
+		}
+	}
+
 	if err != nil {
-		_ = c.Close(ctx, correlationId)
-		cancel()
+		_ = c.Close(closeCtx, correlationId)
+		if parentCtx.Err() != nil {
+			c.Logger().Info(ctx, correlationId, "Context was cancelled while starting")
+			return NewRunResult(ExitCodeSuccess, TerminationCancelled, nil)
+		}
 		c.Logger().Fatal(ctx, correlationId, err, "Process is terminated")
-		os.Exit(1)
-		return
+		return NewRunResult(ExitCodeFailure, TerminationStartFailed, err)
 	}
 
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGABRT)
+	for {
+		select {
+		case err := <-c.feedbackWithErrorChan:
+			exitCode, shutdownErr := c.shutdown(closeCtx, correlationId, signals)
+			c.Logger().Fatal(ctx, correlationId, err, "Process is terminated")
+			switch exitCode {
+			case ExitCodeForced, ExitCodeShutdownTimeout:
+				// Report the failed shutdown caused by the failure
+				if appErr, ok := shutdownErr.(*cerr.ApplicationError); ok {
+					err = appErr.WithCause(err)
+				}
+			default:
+				exitCode = ExitCodeFailure
+			}
+			return NewRunResult(exitCode, TerminationError, err)
+		case <-c.feedbackChan:
+			exitCode, err := c.shutdown(closeCtx, correlationId, signals)
+			c.Logger().Info(ctx, correlationId, "Goodbye!")
+			return NewRunResult(exitCode, TerminationShutdown, err)
+		case sig := <-signals:
+			// Reload configuration on SIGHUP instead of terminating
+			if sig == syscall.SIGHUP {
+				c.Logger().Info(ctx, correlationId, "Reloading configuration from %s", path)
+				_ = c.ReloadConfig(ctx, correlationId)
+				continue
+			}
 
-	select {
-	case err := <-c.feedbackWithErrorChan:
-		msg := cconv.StringConverter.ToString(err)
-		err = errors.New(msg)
-		_ = c.Close(ctx, correlationId)
-		cancel()
-		c.Logger().Fatal(ctx, correlationId, err, "Process is terminated")
-		os.Exit(1)
-		break
-	case <-c.feedbackChan:
-		_ = c.Close(ctx, correlationId)
-		cancel()
-		c.Logger().Info(ctx, correlationId, "Goodbye!")
-		os.Exit(0)
-		break
-	case <-ch:
-		_ = c.Close(ctx, correlationId)
-		cancel()
-		c.Logger().Info(ctx, correlationId, "Goodbye!")
-		os.Exit(0)
-		break
+			c.Logger().Info(ctx, correlationId, "Received %v signal, stopping container", sig)
+			exitCode, err := c.shutdown(closeCtx, correlationId, signals)
+			c.Logger().Info(ctx, correlationId, "Goodbye!")
+			return NewRunResult(exitCode, TerminationSignal, err)
+		case <-parentCtx.Done():
+			c.Logger().Info(ctx, correlationId, "Context was cancelled, stopping container")
+			exitCode, err := c.shutdown(closeCtx, correlationId, signals)
+			c.Logger().Info(ctx, correlationId, "Goodbye!")
+			return NewRunResult(exitCode, TerminationCancelled, err)
+		}
 	}
 }
+
+// detachedContext keeps values of the parent context, but is never cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func newDetachedContext(parent context.Context) context.Context {
+	return detachedContext{Context: parent}
+}
+
+func (c detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
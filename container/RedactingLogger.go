@@ -0,0 +1,82 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pip-services3-gox/pip-services3-components-gox/log"
+)
+
+// redactingLogger logger decorator that replaces values resolved from secret references
+// in the container configuration with config.RedactedValue before they are logged.
+//	see config.ContainerConfig.Redact
+type redactingLogger struct {
+	log.ILogger
+	container *Container
+}
+
+func newRedactingLogger(logger log.ILogger, container *Container) log.ILogger {
+	if redacting, ok := logger.(*redactingLogger); ok && redacting.container == container {
+		logger = redacting.ILogger
+	}
+	return &redactingLogger{ILogger: logger, container: container}
+}
+
+func (c *redactingLogger) redact(err error, message string, args []any) (string, error) {
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	conf := c.container.getRedactConfig()
+	return conf.Redact(message), conf.RedactError(err)
+}
+
+// Log a message at specified log level.
+//	Parameters:
+//		- ctx context.Context
+//		- level LevelType a log level.
+//		- correlationId string transaction id to trace execution through call chain.
+//		- err error an error object associated with this message.
+//		- message string a human-readable message to log.
+//		- args ...any arguments to parameterize the message.
+func (c *redactingLogger) Log(ctx context.Context, level log.LevelType, correlationId string,
+	err error, message string, args ...any) {
+
+	message, err = c.redact(err, message, args)
+	c.ILogger.Log(ctx, level, correlationId, err, message)
+}
+
+// Fatal logs fatal (unrecoverable) message that caused the process to crash.
+func (c *redactingLogger) Fatal(ctx context.Context, correlationId string, err error, message string, args ...any) {
+	message, err = c.redact(err, message, args)
+	c.ILogger.Fatal(ctx, correlationId, err, message)
+}
+
+// Error logs recoverable application error.
+func (c *redactingLogger) Error(ctx context.Context, correlationId string, err error, message string, args ...any) {
+	message, err = c.redact(err, message, args)
+	c.ILogger.Error(ctx, correlationId, err, message)
+}
+
+// Warn logs a warning that may or may not have a negative impact.
+func (c *redactingLogger) Warn(ctx context.Context, correlationId string, message string, args ...any) {
+	message, _ = c.redact(nil, message, args)
+	c.ILogger.Warn(ctx, correlationId, message)
+}
+
+// Info logs an important information message.
+func (c *redactingLogger) Info(ctx context.Context, correlationId string, message string, args ...any) {
+	message, _ = c.redact(nil, message, args)
+	c.ILogger.Info(ctx, correlationId, message)
+}
+
+// Debug logs a high-level debug information for troubleshooting.
+func (c *redactingLogger) Debug(ctx context.Context, correlationId string, message string, args ...any) {
+	message, _ = c.redact(nil, message, args)
+	c.ILogger.Debug(ctx, correlationId, message)
+}
+
+// Trace logs a low-level debug information for troubleshooting.
+func (c *redactingLogger) Trace(ctx context.Context, correlationId string, message string, args ...any) {
+	message, _ = c.redact(nil, message, args)
+	c.ILogger.Trace(ctx, correlationId, message)
+}
@@ -0,0 +1,54 @@
+package container
+
+// TerminationReason reason why the process container stopped running.
+type TerminationReason string
+
+//	Termination reasons
+//	TerminationHelp: usage help was printed and the container was not started
+//	TerminationInvalidArguments: command line arguments are invalid and the container was not started
+//	TerminationValidate: the configuration was validated and the container was not started
+//	TerminationPrintConfig: the effective configuration was printed and the container was not started
+//	TerminationGraph: the reference graph was printed and the container was closed
+//	TerminationCommand: a command other than run was completed, see ContainerCommand
+//	TerminationStartFailed: the container failed to read configuration or to open
+//	TerminationSignal: the process received a stop signal
+//	TerminationShutdown: a component requested shutdown with run.SendShutdownSignal
+//	TerminationError: a component requested shutdown with run.SendShutdownSignalWithErr
+//	TerminationPanic: the container recovered from a panic
+//	TerminationCancelled: the parent context was cancelled
+const (
+	TerminationHelp             TerminationReason = "help"
+	TerminationInvalidArguments TerminationReason = "invalid_arguments"
+	TerminationValidate         TerminationReason = "validate"
+	TerminationPrintConfig      TerminationReason = "print_config"
+	TerminationGraph            TerminationReason = "graph"
+	TerminationCommand          TerminationReason = "command"
+	TerminationStartFailed      TerminationReason = "start_failed"
+	TerminationSignal           TerminationReason = "signal"
+	TerminationShutdown         TerminationReason = "shutdown"
+	TerminationError            TerminationReason = "error"
+	TerminationPanic            TerminationReason = "panic"
+	TerminationCancelled        TerminationReason = "cancelled"
+)
+
+// RunResult result of running the process container.
+//	see ProcessContainer.RunWithResult
+type RunResult struct {
+	ExitCode int
+	Reason   TerminationReason
+	Err      error
+}
+
+// NewRunResult creates a new instance of the run result.
+//	Parameters:
+//		- exitCode int the process exit code.
+//		- reason TerminationReason the reason why the container stopped.
+//		- err error an error that terminated the container or nil.
+//	Returns: *RunResult
+func NewRunResult(exitCode int, reason TerminationReason, err error) *RunResult {
+	return &RunResult{
+		ExitCode: exitCode,
+		Reason:   reason,
+		Err:      err,
+	}
+}
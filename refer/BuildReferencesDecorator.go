@@ -2,6 +2,7 @@ package refer
 
 import (
 	"context"
+	"time"
 
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 	"github.com/pip-services3-gox/pip-services3-components-gox/build"
@@ -171,10 +172,16 @@ func (c *BuildReferencesDecorator) Find(locator any, required bool) ([]any, erro
 	components, _ := c.ReferencesDecorator.Find(locator, required)
 
 	if required && len(components) == 0 {
+		start := time.Now()
 		factory := c.FindFactory(locator)
 		component := c.Create(locator, factory)
+		if factory != nil && component == nil {
+			c.Events.NotifyStep(context.TODO(), "", ComponentFailed, locator, nil, start,
+				crefer.NewReferenceError("", locator))
+		}
 		if component != nil {
 			locator = c.ClarifyLocator(locator, factory)
+			c.Events.NotifyStep(context.TODO(), "", ComponentCreated, locator, component, start, nil)
 			// TODO:: check ctx propagation
 			c.ReferencesDecorator.TopReferences.Put(context.TODO(), locator, component)
 			components = append(components, component)
@@ -0,0 +1,79 @@
+package refer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComponentError error raised by a specific component during its lifecycle operation.
+// It carries the component locator to identify the failed component.
+type ComponentError struct {
+	Locator any
+	Err     error
+}
+
+// NewComponentError creates a new instance of the component error.
+//	Parameters:
+//		- locator any a locator of the failed component.
+//		- err error the original error raised by the component.
+//	Returns: *ComponentError
+func NewComponentError(locator any, err error) *ComponentError {
+	return &ComponentError{
+		Locator: locator,
+		Err:     err,
+	}
+}
+
+// Error gets the error message with the component locator.
+//	Returns: string
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Locator, e.Err.Error())
+}
+
+// Unwrap gets the original error raised by the component.
+//	Returns: error
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// OpenError error returned when components fail to open.
+// Components opened before the failure are closed in the reverse order,
+// and errors raised while closing them are kept together with the original failure.
+type OpenError struct {
+	Cause       *ComponentError
+	CloseErrors []*ComponentError
+}
+
+// NewOpenError creates a new instance of the open error.
+//	Parameters:
+//		- cause *ComponentError the original error raised while opening a component.
+//		- closeErrors []*ComponentError errors raised while closing opened components.
+//	Returns: *OpenError
+func NewOpenError(cause *ComponentError, closeErrors []*ComponentError) *OpenError {
+	return &OpenError{
+		Cause:       cause,
+		CloseErrors: closeErrors,
+	}
+}
+
+// Error gets the error message with the original failure and all close failures.
+//	Returns: string
+func (e *OpenError) Error() string {
+	message := "Failed to open " + e.Cause.Error()
+
+	if len(e.CloseErrors) > 0 {
+		closeMessages := make([]string, len(e.CloseErrors))
+		for index, closeErr := range e.CloseErrors {
+			closeMessages[index] = closeErr.Error()
+		}
+		message += "; failed to close opened components: " + strings.Join(closeMessages, "; ")
+	}
+
+	return message
+}
+
+// Unwrap gets the original error raised while opening a component.
+//	Returns: error
+func (e *OpenError) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,297 @@
+package refer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	crun "github.com/pip-services3-gox/pip-services3-commons-gox/run"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+)
+
+// componentSupervisor receives runtime failures of a component and restarts
+// the component according to its restart policy. Components report failures
+// by calling run.SendShutdownSignalWithErr with the context passed to their Open method.
+type componentSupervisor struct {
+	lock      sync.Mutex
+	component any
+	policy    *config.RestartPolicy
+	restarts  int
+	failures  crun.ContextShutdownWithErrorChan
+	stop      chan struct{}
+	// Time since the component runs without failures
+	running time.Time
+}
+
+func newComponentSupervisor(component any, policy *config.RestartPolicy) *componentSupervisor {
+	return &componentSupervisor{
+		component: component,
+		policy:    policy,
+		// Failures are sent without blocking, so keep one of them until it is processed
+		failures: make(crun.ContextShutdownWithErrorChan, 1),
+	}
+}
+
+// start begins receiving failures if the supervisor is not running yet.
+// It returns the stop channel of the current run.
+func (c *componentSupervisor) start() (chan struct{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stop != nil {
+		return c.stop, false
+	}
+
+	c.stop = make(chan struct{})
+	c.restarts = 0
+	c.running = time.Now()
+	return c.stop, true
+}
+
+func (c *componentSupervisor) getPolicy() *config.RestartPolicy {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.policy
+}
+
+func (c *componentSupervisor) setPolicy(policy *config.RestartPolicy) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.policy = policy
+}
+
+// shutdown stops receiving failures.
+func (c *componentSupervisor) shutdown() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+func isStopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetComponentRestartPolicy sets a policy to restart a specific component
+// after it reports a runtime failure through run.SendShutdownSignalWithErr.
+// The component is closed and reopened together with components that depend on it,
+// or with all components for RestartOneForAll mode. When the restart budget
+// is exhausted the failure is sent to the shutdown channel of the context
+// the decorator was opened with. When the component is already supervised,
+// the policy of the running supervisor is updated, so an opened component
+// keeps being restarted after it is reconfigured in place.
+//	see config.RestartPolicy
+//	Parameters:
+//		- component any a component to set the restart policy for.
+//		- policy *config.RestartPolicy a restart policy or nil to never restart the component.
+func (c *RunReferencesDecorator) SetComponentRestartPolicy(component any, policy *config.RestartPolicy) {
+	if policy == nil || policy.Mode == config.RestartNever {
+		c.removeSupervisor(component)
+		return
+	}
+
+	if supervisor := c.getSupervisor(component); supervisor != nil {
+		supervisor.setPolicy(policy)
+		return
+	}
+
+	c.supervisorsLock.Lock()
+	defer c.supervisorsLock.Unlock()
+	c.supervisors = append(c.supervisors, newComponentSupervisor(component, policy))
+}
+
+func (c *RunReferencesDecorator) getSupervisor(component any) *componentSupervisor {
+	c.supervisorsLock.Lock()
+	defer c.supervisorsLock.Unlock()
+
+	for _, supervisor := range c.supervisors {
+		if supervisor.component == component {
+			return supervisor
+		}
+	}
+	return nil
+}
+
+func (c *RunReferencesDecorator) removeSupervisor(component any) {
+	c.supervisorsLock.Lock()
+	defer c.supervisorsLock.Unlock()
+
+	for index, supervisor := range c.supervisors {
+		if supervisor.component == component {
+			supervisor.shutdown()
+			c.supervisors = append(c.supervisors[:index], c.supervisors[index+1:]...)
+			return
+		}
+	}
+}
+
+func (c *RunReferencesDecorator) stopSupervisors() {
+	c.supervisorsLock.Lock()
+	defer c.supervisorsLock.Unlock()
+
+	for _, supervisor := range c.supervisors {
+		supervisor.shutdown()
+	}
+}
+
+// superviseContext starts the supervisor of the component and returns a context
+// that delivers failures reported by the component to the supervisor.
+func (c *RunReferencesDecorator) superviseContext(ctx context.Context, component any) context.Context {
+	supervisor := c.getSupervisor(component)
+	if supervisor == nil {
+		return ctx
+	}
+
+	parentCtx := c.runCtx
+	if parentCtx == nil {
+		parentCtx = ctx
+	}
+
+	if stop, started := supervisor.start(); started {
+		go c.supervise(parentCtx, supervisor, stop)
+	}
+
+	ctx, _ = crun.AddErrShutdownChanToContext(ctx, supervisor.failures)
+	return ctx
+}
+
+func (c *RunReferencesDecorator) supervise(ctx context.Context,
+	supervisor *componentSupervisor, stop chan struct{}) {
+
+	for {
+		select {
+		case <-stop:
+			return
+		case failure := <-supervisor.failures:
+			if isStopped(stop) {
+				return
+			}
+			c.restart(ctx, supervisor, stop, failure)
+		}
+	}
+}
+
+// restart closes the failed component with affected components, waits for the backoff delay
+// and opens them again. Failures to reopen are handled as new failures of the component.
+func (c *RunReferencesDecorator) restart(ctx context.Context,
+	supervisor *componentSupervisor, stop chan struct{}, failure error) {
+
+	component := supervisor.component
+
+	c.lock.Lock()
+	if !c.opened || isStopped(stop) {
+		c.lock.Unlock()
+		return
+	}
+	locator := c.getLocator(component)
+
+	c.Events.Notify(ctx, "", &LifecycleEvent{
+		Type:      ComponentFailed,
+		Locator:   locator,
+		Component: component,
+		Time:      time.Now(),
+		Error:     failure,
+	})
+
+	policy := supervisor.getPolicy()
+	// Failures after a stable period do not exhaust the restart budget
+	if policy.ResetAfter > 0 && time.Since(supervisor.running) >= policy.ResetAfter {
+		supervisor.restarts = 0
+	}
+	if supervisor.restarts >= policy.MaxRestarts {
+		c.lock.Unlock()
+		sendShutdownSignal(ctx, stop, NewComponentError(locator, failure))
+		return
+	}
+
+	delay := policy.GetDelay(supervisor.restarts)
+	supervisor.restarts++
+
+	components := c.getRestartComponents(supervisor.component, policy)
+	for _, restarted := range reverseComponents(components) {
+		_ = c.closeComponentLocked(ctx, "", restarted)
+	}
+	c.lock.Unlock()
+
+	select {
+	case <-stop:
+		return
+	case <-time.After(delay):
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.opened || isStopped(stop) {
+		return
+	}
+
+	for _, restarted := range components {
+		if c.isOpened(restarted) {
+			continue
+		}
+		if err := c.openComponentLocked(ctx, "", restarted); err != nil {
+			// Restart again or give up when the restart budget is exhausted
+			select {
+			case supervisor.failures <- err:
+			default:
+			}
+			return
+		}
+	}
+	supervisor.running = time.Now()
+}
+
+// sendShutdownSignal sends a failure to the shutdown channel of the context.
+// Failures are sent without blocking, so they are lost when the owner of the channel
+// is not receiving at the moment. In that case it waits until the failure is received
+// or the supervisor is stopped.
+func sendShutdownSignal(ctx context.Context, stop chan struct{}, err error) {
+	if crun.SendShutdownSignalWithErr(ctx, err) {
+		return
+	}
+
+	shutdown, ok := ctx.Value(crun.ContextShutdownWithErrorChanType).(crun.ContextShutdownWithErrorChan)
+	if !ok {
+		return
+	}
+
+	select {
+	case shutdown <- err:
+	case <-stop:
+	case <-ctx.Done():
+	}
+}
+
+// getRestartComponents gets components to restart in the order they shall be opened.
+func (c *RunReferencesDecorator) getRestartComponents(component any, policy *config.RestartPolicy) []any {
+	affected := []any{component}
+	if policy.Mode == config.RestartOneForAll {
+		affected = append(affected, c.openedComponents...)
+	} else {
+		affected = append(affected, c.Dependencies.GetDependents(c, component)...)
+	}
+
+	sorted, err := c.Dependencies.Sort("", c)
+	if err != nil {
+		return affected
+	}
+
+	components := make([]any, 0, len(affected))
+	for _, component := range sorted {
+		for _, other := range affected {
+			if other == component {
+				components = append(components, component)
+				break
+			}
+		}
+	}
+	return components
+}
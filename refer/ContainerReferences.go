@@ -3,10 +3,12 @@ package refer
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
-	"github.com/pip-services3-gox/pip-services3-commons-gox/reflect"
+	creflect "github.com/pip-services3-gox/pip-services3-commons-gox/reflect"
 	"github.com/pip-services3-gox/pip-services3-components-gox/build"
 	"github.com/pip-services3-gox/pip-services3-container-gox/config"
 )
@@ -15,6 +17,14 @@ import (
 // created from container configuration.
 type ContainerReferences struct {
 	*ManagedReferences
+	configured []*configuredComponent
+}
+
+type configuredComponent struct {
+	key       string
+	config    *config.ComponentConfig
+	locator   any
+	component any
 }
 
 // NewContainerReferences creates a new instance of the references
@@ -22,19 +32,62 @@ type ContainerReferences struct {
 func NewContainerReferences() *ContainerReferences {
 	return &ContainerReferences{
 		ManagedReferences: NewEmptyManagedReferences(),
+		configured:        make([]*configuredComponent, 0),
 	}
 }
 
 // PutFromConfig puts components into the references from container configuration.
+// Locators listed in the "dependencies" section of component configuration
+// are declared as component dependencies, so the component is opened after them.
 //	Parameters:
 //		- ctx context.Context
 //		- config config.ContainerConfig a container
 //			configuration with information of components to be added.
 //	Returns: error CreateError when one of component cannot be created.
-func (c *ContainerReferences) PutFromConfig(ctx context.Context, config config.ContainerConfig) error {
-	var err error
-	var locator any
-	var component any
+func (c *ContainerReferences) PutFromConfig(ctx context.Context, config config.ContainerConfig) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+
+	keys := componentKeys(config)
+	for index, componentConfig := range config {
+		locator, component, err := c.createFromConfig(ctx, componentConfig)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created component %v\n", locator)
+
+		// Add component to the list
+		c.ManagedReferences.References.Put(ctx, locator, component)
+		c.configureComponent(ctx, componentConfig, component)
+
+		c.configured = append(c.configured, &configuredComponent{
+			key:       keys[index],
+			config:    componentConfig,
+			locator:   locator,
+			component: component,
+		})
+	}
+
+	return nil
+}
+
+// ReloadFromConfig updates components in the references to match a new container configuration.
+// Components missing in the new configuration are closed and removed,
+// new components are created, configured, referenced and opened.
+// Components with changed configuration are recreated, or reconfigured in place
+// when their configuration sets "reconfigurable" parameter to true.
+// Components that depend on recreated or removed components get their references set again.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- config config.ContainerConfig a new container configuration.
+//	Returns: error the first error raised while updating components.
+func (c *ContainerReferences) ReloadFromConfig(ctx context.Context, correlationId string,
+	config config.ContainerConfig) (err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -42,47 +95,289 @@ func (c *ContainerReferences) PutFromConfig(ctx context.Context, config config.C
 		}
 	}()
 
-	for _, componentConfig := range config {
-		if componentConfig.Type != nil {
-			// Create component dynamically
-			locator = componentConfig.Type
-			component, err = reflect.TypeReflector.CreateInstanceByDescriptor(componentConfig.Type)
-		} else if componentConfig.Descriptor != nil {
-			// Or create component statically
+	keys := componentKeys(config)
+	var firstErr error
+	setErr := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Dependents keep references to recreated or removed components until they are referenced again
+	dependents := make([]any, 0)
+	collectDependents := func(configured *configuredComponent) {
+		dependents = append(dependents,
+			c.ManagedReferences.Runner.Dependencies.GetDependents(c, configured.component)...)
+	}
+
+	// Remove components missing in the new configuration
+	for _, configured := range append([]*configuredComponent{}, c.configured...) {
+		if !containsKey(keys, configured.key) {
+			collectDependents(configured)
+			setErr(c.removeConfigured(ctx, correlationId, configured))
+		}
+	}
+
+	for index, componentConfig := range config {
+		configured := c.findConfigured(keys[index])
+
+		if configured == nil {
+			setErr(c.addConfigured(ctx, correlationId, keys[index], componentConfig))
+			continue
+		}
+
+		if sameConfig(configured.config.Config, componentConfig.Config) {
+			continue
+		}
+
+		if componentConfig.Config.GetAsBoolean("reconfigurable") {
+			// Reconfigure component in place
+			configured.config = componentConfig
+			c.ManagedReferences.Runner.updateReferences(func() {
+				c.ManagedReferences.Runner.Dependencies.RemoveDependencies(configured.component)
+			})
+			c.configureComponent(ctx, componentConfig, configured.component)
+			continue
+		}
+
+		// Recreate component with the new configuration
+		collectDependents(configured)
+		if err := c.removeConfigured(ctx, correlationId, configured); err != nil {
+			setErr(err)
+			continue
+		}
+		setErr(c.addConfigured(ctx, correlationId, keys[index], componentConfig))
+	}
+
+	c.setDependentReferences(ctx, correlationId, dependents)
+	return firstErr
+}
+
+// setDependentReferences sets references again to dependent components that are still in the references.
+// Recreated dependents already got references to the new components.
+func (c *ContainerReferences) setDependentReferences(ctx context.Context, correlationId string,
+	dependents []any) {
+
+	if !c.ManagedReferences.Linker.IsOpen() {
+		return
+	}
+
+	components := c.ManagedReferences.References.GetAll()
+	referenced := make([]any, 0, len(dependents))
+	for _, dependent := range dependents {
+		if containsComponent(components, dependent) && !containsComponent(referenced, dependent) {
+			referenced = append(referenced, dependent)
+			c.ManagedReferences.Linker.setReferences(ctx, correlationId, dependent)
+		}
+	}
+}
+
+func (c *ContainerReferences) createFromConfig(ctx context.Context,
+	componentConfig *config.ComponentConfig) (locator any, component any, err error) {
+
+	start := time.Now()
+	defer func() {
+		if locator == nil {
 			locator = componentConfig.Descriptor
-			factory := c.ManagedReferences.Builder.FindFactory(locator)
-			component = c.ManagedReferences.Builder.Create(locator, factory)
-			if component == nil {
-				return refer.NewReferenceError("", locator)
+			if locator == nil {
+				locator = componentConfig.Type
 			}
-			locator = c.ManagedReferences.Builder.ClarifyLocator(locator, factory)
 		}
+		c.Events.NotifyStep(ctx, "", ComponentCreated, locator, component, start, err)
+	}()
 
-		// Check that component was created
+	if componentConfig.Type != nil {
+		// Create component dynamically
+		locator = componentConfig.Type
+		component, err = creflect.TypeReflector.CreateInstanceByDescriptor(componentConfig.Type)
+	} else if componentConfig.Descriptor != nil {
+		// Or create component statically
+		locator = componentConfig.Descriptor
+		factory := c.ManagedReferences.Builder.FindFactory(locator)
+		component = c.ManagedReferences.Builder.Create(locator, factory)
 		if component == nil {
-			return build.NewCreateError(
-				"CANNOT_CREATE_COMPONENT",
-				"Cannot create component",
-			).WithDetails("config", config)
+			return locator, nil, refer.NewReferenceError("", locator)
 		}
+		locator = c.ManagedReferences.Builder.ClarifyLocator(locator, factory)
+	}
 
-		fmt.Printf("Created component %v\n", locator)
+	// Check that component was created
+	if component == nil {
+		createErr := build.NewCreateError(
+			"CANNOT_CREATE_COMPONENT",
+			"Cannot create component",
+		).WithDetails("config", componentConfig.GetRedactedConfig())
+		if err != nil {
+			createErr.WithCause(err)
+		}
+		return locator, nil, createErr
+	}
 
-		// Add component to the list
+	return locator, component, nil
+}
+
+func (c *ContainerReferences) configureComponent(ctx context.Context,
+	componentConfig *config.ComponentConfig, component any) {
+
+	// Declare component dependencies to open components in the right order
+	dependencies := c.readDependencies(componentConfig.Config)
+	c.ManagedReferences.Runner.updateReferences(func() {
+		c.ManagedReferences.Runner.Dependencies.AddDependencies(component, dependencies...)
+		c.ManagedReferences.Runner.SetComponentTimeouts(
+			component, componentConfig.OpenTimeout, componentConfig.CloseTimeout,
+		)
+	})
+	c.ManagedReferences.Runner.SetComponentRestartPolicy(component, componentConfig.Restart)
+
+	// Configure component
+	if configurable, ok := component.(cconfig.IConfigurable); ok {
+		start := time.Now()
+		configurable.Configure(ctx, componentConfig.Config)
+		c.Events.NotifyStep(ctx, "", ComponentConfigured, c.getLocator(component), component, start, nil)
+	}
+
+	// Set references to factories
+	if _, ok := component.(build.IFactory); ok {
+		if referenceable, ok := component.(refer.IReferenceable); ok {
+			start := time.Now()
+			referenceable.SetReferences(ctx, c)
+			c.Events.NotifyStep(ctx, "", ComponentReferenced, c.getLocator(component), component, start, nil)
+		}
+	}
+}
+
+// addConfigured creates a new component and brings it to the same state as the references.
+func (c *ContainerReferences) addConfigured(ctx context.Context, correlationId string,
+	key string, componentConfig *config.ComponentConfig) error {
+
+	locator, component, err := c.createFromConfig(ctx, componentConfig)
+	if err != nil {
+		return err
+	}
+
+	c.ManagedReferences.Runner.updateReferences(func() {
 		c.ManagedReferences.References.Put(ctx, locator, component)
+	})
+	c.configureComponent(ctx, componentConfig, component)
+
+	c.configured = append(c.configured, &configuredComponent{
+		key:       key,
+		config:    componentConfig,
+		locator:   locator,
+		component: component,
+	})
+
+	if c.ManagedReferences.Linker.IsOpen() {
+		c.ManagedReferences.Linker.setReferences(ctx, correlationId, component)
+	}
+	return c.ManagedReferences.Runner.openComponent(ctx, correlationId, component)
+}
+
+// removeConfigured closes, unreferences and removes a component created from configuration.
+func (c *ContainerReferences) removeConfigured(ctx context.Context, correlationId string,
+	configured *configuredComponent) error {
+
+	// Stop the supervisor first, so a failure reported meanwhile does not reopen the component
+	c.ManagedReferences.Runner.removeSupervisor(configured.component)
+	err := c.ManagedReferences.Runner.closeComponent(ctx, correlationId, configured.component)
+
+	if c.ManagedReferences.Linker.IsOpen() {
+		c.ManagedReferences.Linker.unsetReferences(ctx, correlationId, configured.locator, configured.component)
+	}
+	c.ManagedReferences.Runner.updateReferences(func() {
+		c.ManagedReferences.References.Remove(ctx, configured.component)
+		c.ManagedReferences.Runner.Dependencies.RemoveDependencies(configured.component)
+		c.ManagedReferences.Runner.removeTimeouts(configured.component)
+	})
+
+	for index, other := range c.configured {
+		if other == configured {
+			c.configured = append(c.configured[:index], c.configured[index+1:]...)
+			break
+		}
+	}
+
+	return err
+}
+
+func (c *ContainerReferences) findConfigured(key string) *configuredComponent {
+	for _, configured := range c.configured {
+		if configured.key == key {
+			return configured
+		}
+	}
+	return nil
+}
+
+// componentKeys gets keys that identify components in container configuration.
+// The key is the component descriptor or type, and repeated keys get an index suffix.
+func componentKeys(config config.ContainerConfig) []string {
+	keys := make([]string, len(config))
+	counts := make(map[string]int)
 
-		// Configure component
-		if configurable, ok := component.(cconfig.IConfigurable); ok {
-			configurable.Configure(ctx, componentConfig.Config)
+	for index, componentConfig := range config {
+		key := ""
+		if componentConfig.Descriptor != nil {
+			key = componentConfig.Descriptor.String()
+		} else if componentConfig.Type != nil {
+			key = componentConfig.Type.String()
 		}
 
-		// Set references to factories
-		if _, ok := component.(build.IFactory); ok {
-			if referenceable, ok := component.(refer.IReferenceable); ok {
-				referenceable.SetReferences(ctx, c)
+		counts[key]++
+		if counts[key] > 1 {
+			key = fmt.Sprintf("%s#%d", key, counts[key])
+		}
+		keys[index] = key
+	}
+
+	return keys
+}
+
+func sameConfig(config1 *cconfig.ConfigParams, config2 *cconfig.ConfigParams) bool {
+	if config1 == nil || config2 == nil {
+		return config1 == config2
+	}
+	return reflect.DeepEqual(config1.Value(), config2.Value())
+}
+
+func containsComponent(components []any, component any) bool {
+	for _, value := range components {
+		if value == component {
+			return true
+		}
+	}
+	return false
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, value := range keys {
+		if value == key {
+			return true
+		}
+	}
+	return false
+}
+
+// readDependencies reads locators of component dependencies
+// from the "dependencies" section of component configuration.
+func (c *ContainerReferences) readDependencies(config *cconfig.ConfigParams) []any {
+	locators := make([]any, 0)
+	if config == nil {
+		return locators
+	}
+
+	dependencies := config.GetSection("dependencies")
+
+	for _, name := range dependencies.Keys() {
+		if locator, ok := dependencies.GetAsNullableString(name); ok && locator != "" {
+			descriptor, err := refer.ParseDescriptorFromString(locator)
+			if err == nil {
+				locators = append(locators, descriptor)
+			} else {
+				locators = append(locators, locator)
 			}
 		}
 	}
 
-	return err
+	return locators
 }
@@ -0,0 +1,371 @@
+package refer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+)
+
+// DependencyGraph keeps dependencies declared by components and sorts components
+// so each component comes after all components it depends on.
+// Dependencies are declared as locators (descriptors) and are matched
+// against locators of components registered in the references.
+//
+//	Example:
+//		graph := NewDependencyGraph()
+//		graph.AddDependencies(controller,
+//			crefer.NewDescriptor("mygroup", "persistence", "*", "*", "1.0"),
+//		)
+//
+//		components, err := graph.Sort("123", references)
+type DependencyGraph struct {
+	dependencies []*componentDependencies
+}
+
+type componentDependencies struct {
+	component any
+	locators  []any
+}
+
+type dependencyNode struct {
+	locator    any
+	component  any
+	dependsOn  []int
+	dependents []int
+}
+
+// NewDependencyGraph creates a new empty instance of the dependency graph.
+//	Returns: *DependencyGraph
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		dependencies: make([]*componentDependencies, 0),
+	}
+}
+
+// AddDependencies declares dependencies of a component.
+//	Parameters:
+//		- component any a component that depends on other components.
+//		- locators ...any locators (descriptors) of components it depends on.
+func (c *DependencyGraph) AddDependencies(component any, locators ...any) {
+	if component == nil || len(locators) == 0 {
+		return
+	}
+
+	for _, dependencies := range c.dependencies {
+		if dependencies.component == component {
+			dependencies.locators = append(dependencies.locators, locators...)
+			return
+		}
+	}
+
+	c.dependencies = append(c.dependencies, &componentDependencies{
+		component: component,
+		locators:  locators,
+	})
+}
+
+// GetDependencies gets dependencies declared by a component.
+//	Parameters:
+//		- component any a component to get dependencies for.
+//	Returns: []any a list of locators the component depends on.
+func (c *DependencyGraph) GetDependencies(component any) []any {
+	for _, dependencies := range c.dependencies {
+		if dependencies.component == component {
+			return dependencies.locators
+		}
+	}
+	return []any{}
+}
+
+// RemoveDependencies removes all dependencies declared by a component.
+//	Parameters:
+//		- component any a component to remove dependencies for.
+func (c *DependencyGraph) RemoveDependencies(component any) {
+	for index, dependencies := range c.dependencies {
+		if dependencies.component == component {
+			c.dependencies = append(c.dependencies[:index], c.dependencies[index+1:]...)
+			return
+		}
+	}
+}
+
+// Sort orders components registered in the references, so each component
+// comes after all components it depends on. Components without dependencies
+// between them keep the order they were added to the references.
+//	Parameters:
+//		- correlationId string transaction id to trace execution through call chain.
+//		- references crefer.IReferences references with components to be sorted.
+//	Returns: []any, error components in topological order and
+//		InvalidStateError when components have circular dependencies.
+func (c *DependencyGraph) Sort(correlationId string, references crefer.IReferences) ([]any, error) {
+	nodes := c.buildNodes(references)
+
+	inDegree := make([]int, len(nodes))
+	for index, node := range nodes {
+		inDegree[index] = len(node.dependsOn)
+	}
+
+	result := make([]any, 0, len(nodes))
+	sorted := make([]bool, len(nodes))
+	for len(result) < len(nodes) {
+		// Pick the first ready node to keep insertion order stable
+		next := -1
+		for index := range nodes {
+			if !sorted[index] && inDegree[index] == 0 {
+				next = index
+				break
+			}
+		}
+
+		if next < 0 {
+			return nil, c.newCycleError(correlationId, nodes, sorted)
+		}
+
+		sorted[next] = true
+		result = append(result, nodes[next].component)
+		for _, dependent := range nodes[next].dependents {
+			inDegree[dependent]--
+		}
+	}
+
+	return result, nil
+}
+
+// ForEachParallel calls an action for all components registered in the references.
+// Components that do not depend on each other are processed concurrently.
+// A component is processed only after all components it depends on,
+// or when reverse is true, after all components that depend on it.
+// After the first failed action no new actions are started, and the method
+// waits for running actions to complete. The context is passed to actions as is
+// and is never cancelled, since components may keep using it.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- references crefer.IReferences references with components to be processed.
+//		- maxConcurrency int maximum number of concurrent actions or 0 for unlimited.
+//		- reverse bool true to process dependent components first.
+//		- action func(ctx context.Context, component any) error an action to call for each component.
+//	Returns: []any, error components processed successfully in the order they completed and
+//		the first error returned by action or InvalidStateError when components have circular dependencies.
+func (c *DependencyGraph) ForEachParallel(ctx context.Context, correlationId string,
+	references crefer.IReferences, maxConcurrency int, reverse bool,
+	action func(ctx context.Context, component any) error) ([]any, error) {
+
+	// Check for circular dependencies before starting anything
+	if _, err := c.Sort(correlationId, references); err != nil {
+		return nil, err
+	}
+
+	nodes := c.buildNodes(references)
+	if maxConcurrency <= 0 || maxConcurrency > len(nodes) {
+		maxConcurrency = len(nodes)
+	}
+
+	waitsFor := make([]int, len(nodes))
+	for index, node := range nodes {
+		if reverse {
+			waitsFor[index] = len(node.dependents)
+		} else {
+			waitsFor[index] = len(node.dependsOn)
+		}
+	}
+
+	type actionResult struct {
+		index int
+		err   error
+	}
+
+	results := make(chan actionResult)
+	started := make([]bool, len(nodes))
+	processed := make([]any, 0, len(nodes))
+	running := 0
+	completed := 0
+	var firstErr error
+
+	for completed < len(nodes) {
+		// Start all ready components within the concurrency limit
+		if firstErr == nil {
+			for index := range nodes {
+				if running >= maxConcurrency {
+					break
+				}
+				if started[index] || waitsFor[index] > 0 {
+					continue
+				}
+
+				started[index] = true
+				running++
+				go func(index int) {
+					err := runAction(ctx, action, nodes[index].component)
+					results <- actionResult{index: index, err: err}
+				}(index)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		completed++
+
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+
+		processed = append(processed, nodes[result.index].component)
+		next := nodes[result.index].dependents
+		if reverse {
+			next = nodes[result.index].dependsOn
+		}
+		for _, index := range next {
+			waitsFor[index]--
+		}
+	}
+
+	return processed, firstErr
+}
+
+func runAction(ctx context.Context, action func(ctx context.Context, component any) error,
+	component any) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			recoverErr, ok := r.(error)
+			if !ok {
+				msg := cconv.StringConverter.ToString(r)
+				recoverErr = errors.New(msg)
+			}
+			err = recoverErr
+		}
+	}()
+
+	return action(ctx, component)
+}
+
+func (c *DependencyGraph) buildNodes(references crefer.IReferences) []*dependencyNode {
+	locators := references.GetAllLocators()
+	components := references.GetAll()
+
+	nodes := make([]*dependencyNode, len(components))
+	for index, component := range components {
+		nodes[index] = &dependencyNode{
+			locator:   locators[index],
+			component: component,
+		}
+	}
+
+	for index, node := range nodes {
+		for _, locator := range c.GetDependencies(node.component) {
+			for other, dependency := range nodes {
+				if other == index || containsIndex(node.dependsOn, other) {
+					continue
+				}
+				if crefer.NewReference(dependency.locator, dependency.component).Match(locator) {
+					node.dependsOn = append(node.dependsOn, other)
+					dependency.dependents = append(dependency.dependents, index)
+				}
+			}
+		}
+	}
+
+	return nodes
+}
+
+func (c *DependencyGraph) newCycleError(correlationId string, nodes []*dependencyNode, sorted []bool) error {
+	// Walk dependencies from any unsorted node until a node repeats.
+	// Every unsorted node has at least one unsorted dependency.
+	start := -1
+	for index := range nodes {
+		if !sorted[index] {
+			start = index
+			break
+		}
+	}
+
+	path := make([]int, 0)
+	visited := make(map[int]int)
+	current := start
+	for {
+		if position, ok := visited[current]; ok {
+			path = path[position:]
+			break
+		}
+		visited[current] = len(path)
+		path = append(path, current)
+
+		for _, dependency := range nodes[current].dependsOn {
+			if !sorted[dependency] {
+				current = dependency
+				break
+			}
+		}
+	}
+
+	cycle := make([]string, 0, len(path)+1)
+	for _, index := range path {
+		cycle = append(cycle, fmt.Sprintf("%v", nodes[index].locator))
+	}
+	cycle = append(cycle, cycle[0])
+
+	return cerr.NewInvalidStateError(
+		correlationId,
+		"CIRCULAR_DEPENDENCY",
+		"Components have circular dependency: "+strings.Join(cycle, " -> "),
+	).WithDetails("cycle", cycle)
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, value := range indexes {
+		if value == index {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDependents gets components registered in the references that directly
+// or transitively depend on the specified component.
+//	Parameters:
+//		- references crefer.IReferences references with registered components.
+//		- component any a component to get dependents for.
+//	Returns: []any dependent components in the order they were added to the references.
+func (c *DependencyGraph) GetDependents(references crefer.IReferences, component any) []any {
+	nodes := c.buildNodes(references)
+
+	found := make([]bool, len(nodes))
+	queue := make([]int, 0)
+	for index, node := range nodes {
+		if node.component == component {
+			queue = append(queue, index)
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range nodes[current].dependents {
+			if !found[dependent] && nodes[dependent].component != component {
+				found[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	result := make([]any, 0)
+	for index, node := range nodes {
+		if found[index] {
+			result = append(result, node.component)
+		}
+	}
+	return result
+}
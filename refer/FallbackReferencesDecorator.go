@@ -0,0 +1,86 @@
+package refer
+
+import (
+	"sync"
+
+	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+)
+
+// FallbackReferencesDecorator references decorator that searches components
+// in parent references when no local component matches a locator.
+// Parent components are only located, they are not listed, linked or opened
+// by the local references, so the parent keeps managing their lifecycle.
+type FallbackReferencesDecorator struct {
+	*ReferencesDecorator
+	lock   sync.RWMutex
+	parent crefer.IReferences
+}
+
+// NewFallbackReferencesDecorator creates a new instance of the decorator.
+//	Parameters:
+//		- nextReferences crefer.IReferences the next references or decorator in the chain.
+//		- topReferences crefer.IReferences the decorator at the top of the chain.
+//	Returns: *FallbackReferencesDecorator
+func NewFallbackReferencesDecorator(nextReferences crefer.IReferences,
+	topReferences crefer.IReferences) *FallbackReferencesDecorator {
+
+	return &FallbackReferencesDecorator{
+		ReferencesDecorator: NewReferencesDecorator(nextReferences, topReferences),
+	}
+}
+
+// GetParent gets parent references.
+//	Returns: crefer.IReferences parent references or nil if they are not set.
+func (c *FallbackReferencesDecorator) GetParent() crefer.IReferences {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.parent
+}
+
+// SetParent sets parent references to search components that are missing locally.
+//	Parameters:
+//		- parent crefer.IReferences parent references or nil to disable the fallback.
+func (c *FallbackReferencesDecorator) SetParent(parent crefer.IReferences) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.parent = parent
+}
+
+// SetNextReferences replaces the next references in the chain.
+// Unlike setting NextReferences directly, it is safe while components are being located.
+//	Parameters:
+//		- nextReferences crefer.IReferences the next references or decorator in the chain.
+func (c *FallbackReferencesDecorator) SetNextReferences(nextReferences crefer.IReferences) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.NextReferences = nextReferences
+}
+
+// Find all component references that match specified locator.
+// When no local components match the locator, it searches them in the parent references.
+//	Parameters:
+//		- locator any the locator to find a reference by.
+//		- required bool forces to raise an exception if no reference is found.
+//	Returns: []any, error a list with matching component references and
+//		a ReferenceError when required is set to true but no references found
+func (c *FallbackReferencesDecorator) Find(locator any, required bool) ([]any, error) {
+	c.lock.RLock()
+	parent, next := c.parent, c.NextReferences
+	c.lock.RUnlock()
+
+	if parent == nil {
+		return next.Find(locator, required)
+	}
+
+	components, _ := next.Find(locator, false)
+	if len(components) > 0 {
+		return components, nil
+	}
+
+	components, _ = parent.Find(locator, false)
+	if len(components) > 0 {
+		return components, nil
+	}
+
+	return next.Find(locator, required)
+}
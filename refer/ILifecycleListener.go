@@ -0,0 +1,71 @@
+package refer
+
+import (
+	"context"
+	"time"
+)
+
+// LifecycleEventType type of component or container lifecycle event.
+type LifecycleEventType string
+
+//	Types of lifecycle events
+//	ComponentCreated: component was created by a factory or by its type
+//	ComponentConfigured: component received its configuration parameters
+//	ComponentReferenced: component received references to other components
+//	ComponentOpened: component was opened
+//	ComponentClosed: component was closed
+//	ComponentUnreferenced: component released references to other components
+//	ComponentFailed: component failed to be created, opened or closed
+//	ContainerStarting: container started to create and open components
+//	ContainerStarted: container opened all components or failed to start
+//	ContainerStopping: container started to close components
+//	ContainerStopped: container closed all components
+const (
+	ComponentCreated      LifecycleEventType = "component_created"
+	ComponentConfigured   LifecycleEventType = "component_configured"
+	ComponentReferenced   LifecycleEventType = "component_referenced"
+	ComponentOpened       LifecycleEventType = "component_opened"
+	ComponentClosed       LifecycleEventType = "component_closed"
+	ComponentUnreferenced LifecycleEventType = "component_unreferenced"
+	ComponentFailed       LifecycleEventType = "component_failed"
+	ContainerStarting     LifecycleEventType = "container_starting"
+	ContainerStarted      LifecycleEventType = "container_started"
+	ContainerStopping     LifecycleEventType = "container_stopping"
+	ContainerStopped      LifecycleEventType = "container_stopped"
+)
+
+// LifecycleEvent describes a step in the lifecycle of a component or container.
+//	Fields:
+//		- Type LifecycleEventType the type of the event.
+//		- Locator any a locator of the component or the container name.
+//		- Component any the component or the container itself.
+//		- Time time.Time time when the step started.
+//		- Duration time.Duration time spent by the step.
+//		- Error error an error raised by the step or nil if it succeeded.
+type LifecycleEvent struct {
+	Type      LifecycleEventType
+	Locator   any
+	Component any
+	Time      time.Time
+	Duration  time.Duration
+	Error     error
+}
+
+// ILifecycleListener interface for components that listen to lifecycle events
+// of other components and the container. Listeners can be registered in the container
+// or added to the container as regular components.
+//	see LifecycleEvents
+//	Example:
+//		type MyAuditor struct {}
+//
+//		func (c *MyAuditor) OnLifecycleEvent(ctx context.Context, correlationId string, event *LifecycleEvent) {
+//			fmt.Printf("%s %v in %v\n", event.Type, event.Locator, event.Duration)
+//		}
+type ILifecycleListener interface {
+	// OnLifecycleEvent is called when a lifecycle event happens.
+	//	Parameters:
+	//		- ctx context.Context
+	//		- correlationId string transaction id to trace execution through call chain.
+	//		- event *LifecycleEvent the lifecycle event.
+	OnLifecycleEvent(ctx context.Context, correlationId string, event *LifecycleEvent)
+}
@@ -0,0 +1,146 @@
+package refer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+)
+
+// LifecycleEvents dispatches lifecycle events to listeners registered in code
+// and to components in the references that implement ILifecycleListener interface.
+// Events can be sent concurrently when components are opened or closed in parallel.
+//	see ILifecycleListener
+type LifecycleEvents struct {
+	lock       sync.RWMutex
+	listeners  []ILifecycleListener
+	references crefer.IReferences
+}
+
+// NewLifecycleEvents creates a new instance of the lifecycle events dispatcher.
+//	Returns: *LifecycleEvents
+func NewLifecycleEvents() *LifecycleEvents {
+	return &LifecycleEvents{
+		listeners: make([]ILifecycleListener, 0),
+	}
+}
+
+// SetReferences sets references to discover listeners among components.
+//	Parameters:
+//		- ctx context.Context
+//		- references crefer.IReferences references to search for listeners or nil to stop discovery.
+func (c *LifecycleEvents) SetReferences(ctx context.Context, references crefer.IReferences) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.references = references
+}
+
+// AddListener registers a listener to receive lifecycle events.
+//	Parameters:
+//		- listener ILifecycleListener a listener to be added.
+func (c *LifecycleEvents) AddListener(listener ILifecycleListener) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// RemoveListener removes a previously registered listener.
+//	Parameters:
+//		- listener ILifecycleListener a listener to be removed.
+func (c *LifecycleEvents) RemoveListener(listener ILifecycleListener) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for index, other := range c.listeners {
+		if other == listener {
+			c.listeners = append(c.listeners[:index], c.listeners[index+1:]...)
+			return
+		}
+	}
+}
+
+// GetListeners gets all registered and discovered listeners.
+//	Returns: []ILifecycleListener a list of listeners.
+func (c *LifecycleEvents) GetListeners() []ILifecycleListener {
+	c.lock.RLock()
+	listeners := append([]ILifecycleListener{}, c.listeners...)
+	references := c.references
+	c.lock.RUnlock()
+
+	if references != nil {
+		for _, component := range references.GetAll() {
+			if listener, ok := component.(ILifecycleListener); ok && !containsListener(listeners, listener) {
+				listeners = append(listeners, listener)
+			}
+		}
+	}
+
+	return listeners
+}
+
+// Notify sends a lifecycle event to all listeners.
+// Panics raised by listeners are recovered, so they do not affect the lifecycle.
+// It is safe to call the method on nil dispatcher.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- event *LifecycleEvent the lifecycle event to send.
+func (c *LifecycleEvents) Notify(ctx context.Context, correlationId string, event *LifecycleEvent) {
+	if c == nil {
+		return
+	}
+
+	for _, listener := range c.GetListeners() {
+		notifyListener(ctx, correlationId, listener, event)
+	}
+}
+
+// NotifyStep sends an event for a completed lifecycle step.
+// When the step failed it sends ComponentFailed event instead of the given type.
+//	Parameters:
+//		- ctx context.Context
+//		- correlationId string transaction id to trace execution through call chain.
+//		- eventType LifecycleEventType the type of the event when the step succeeded.
+//		- locator any a locator of the component.
+//		- component any the component.
+//		- start time.Time time when the step started.
+//		- err error an error raised by the step or nil.
+func (c *LifecycleEvents) NotifyStep(ctx context.Context, correlationId string, eventType LifecycleEventType,
+	locator any, component any, start time.Time, err error) {
+
+	if c == nil {
+		return
+	}
+
+	if err != nil {
+		eventType = ComponentFailed
+	}
+
+	c.Notify(ctx, correlationId, &LifecycleEvent{
+		Type:      eventType,
+		Locator:   locator,
+		Component: component,
+		Time:      start,
+		Duration:  time.Since(start),
+		Error:     err,
+	})
+}
+
+func notifyListener(ctx context.Context, correlationId string,
+	listener ILifecycleListener, event *LifecycleEvent) {
+
+	defer func() {
+		recover()
+	}()
+
+	listener.OnLifecycleEvent(ctx, correlationId, event)
+}
+
+func containsListener(listeners []ILifecycleListener, listener ILifecycleListener) bool {
+	for _, other := range listeners {
+		if other == listener {
+			return true
+		}
+	}
+	return false
+}
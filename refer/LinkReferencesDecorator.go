@@ -2,6 +2,7 @@ package refer
 
 import (
 	"context"
+	"time"
 
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 )
@@ -10,8 +11,10 @@ import (
 // to newly added components that implement IReferenceable
 // interface and unsets references from removed components
 // that implement IUnreferenceable interface.
+// When Graph is set, lookups that components make through the references are recorded in it.
 type LinkReferencesDecorator struct {
 	*ReferencesDecorator
+	Graph  *ReferenceGraph
 	opened bool
 }
 
@@ -42,7 +45,9 @@ func (c *LinkReferencesDecorator) Open(ctx context.Context, correlationId string
 	if !c.opened {
 		c.opened = true
 		components := c.GetAll()
-		crefer.Referencer.SetReferences(ctx, c.ReferencesDecorator.TopReferences, components)
+		for _, component := range components {
+			c.setReferences(ctx, correlationId, component)
+		}
 	}
 	return nil
 }
@@ -56,7 +61,9 @@ func (c *LinkReferencesDecorator) Close(ctx context.Context, correlationId strin
 	if c.opened {
 		c.opened = false
 		components := c.GetAll()
-		crefer.Referencer.UnsetReferences(ctx, components)
+		for _, component := range components {
+			c.unsetReferences(ctx, correlationId, c.getLocator(component), component)
+		}
 	}
 	return nil
 }
@@ -70,7 +77,7 @@ func (c *LinkReferencesDecorator) Put(ctx context.Context, locator any, componen
 	c.ReferencesDecorator.Put(ctx, locator, component)
 
 	if c.opened {
-		crefer.Referencer.SetReferencesForOne(ctx, c.ReferencesDecorator.TopReferences, component)
+		c.setReferences(ctx, "", component)
 	}
 }
 
@@ -85,8 +92,8 @@ func (c *LinkReferencesDecorator) Put(ctx context.Context, locator any, componen
 func (c *LinkReferencesDecorator) Remove(ctx context.Context, locator any) any {
 	component := c.ReferencesDecorator.Remove(ctx, locator)
 
-	if c.opened {
-		crefer.Referencer.UnsetReferencesForOne(ctx, component)
+	if c.opened && component != nil {
+		c.unsetReferences(ctx, "", locator, component)
 	}
 
 	return component
@@ -101,8 +108,37 @@ func (c *LinkReferencesDecorator) RemoveAll(ctx context.Context, locator any) []
 	components := c.NextReferences.RemoveAll(ctx, locator)
 
 	if c.opened {
-		crefer.Referencer.UnsetReferences(ctx, components)
+		for _, component := range components {
+			c.unsetReferences(ctx, "", locator, component)
+		}
 	}
 
 	return components
 }
+
+func (c *LinkReferencesDecorator) setReferences(ctx context.Context, correlationId string, component any) {
+	if _, ok := component.(crefer.IReferenceable); !ok {
+		return
+	}
+
+	references := c.ReferencesDecorator.TopReferences
+	if c.Graph != nil {
+		references = newTrackingReferences(references, c.Graph, c.getLocator(component))
+	}
+
+	start := time.Now()
+	crefer.Referencer.SetReferencesForOne(ctx, references, component)
+	c.Events.NotifyStep(ctx, correlationId, ComponentReferenced, c.getLocator(component), component, start, nil)
+}
+
+func (c *LinkReferencesDecorator) unsetReferences(ctx context.Context, correlationId string,
+	locator any, component any) {
+
+	if _, ok := component.(crefer.IUnreferenceable); !ok {
+		return
+	}
+
+	start := time.Now()
+	crefer.Referencer.UnsetReferencesForOne(ctx, component)
+	c.Events.NotifyStep(ctx, correlationId, ComponentUnreferenced, locator, component, start, nil)
+}
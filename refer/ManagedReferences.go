@@ -12,9 +12,11 @@ import (
 //	Auto-linking newly added components
 //	Auto-opening newly added components
 //	Auto-closing removed components
+//	Fallback to parent references for missing components
 type ManagedReferences struct {
 	*ReferencesDecorator
 	References *crefer.References
+	Fallback   *FallbackReferencesDecorator
 	Builder    *BuildReferencesDecorator
 	Linker     *LinkReferencesDecorator
 	Runner     *RunReferencesDecorator
@@ -32,12 +34,15 @@ func NewManagedReferences(ctx context.Context, tuples []any) *ManagedReferences
 	}
 
 	c.References = crefer.NewReferences(ctx, tuples)
-	c.Builder = NewBuildReferencesDecorator(c.References, c)
+	c.Fallback = NewFallbackReferencesDecorator(c.References, c)
+	c.Builder = NewBuildReferencesDecorator(c.Fallback, c)
 	c.Linker = NewLinkReferencesDecorator(c.Builder, c)
 	c.Runner = NewRunReferencesDecorator(c.Linker, c)
 
 	c.ReferencesDecorator.NextReferences = c.Runner
 
+	c.SetEvents(ctx, NewLifecycleEvents())
+
 	return c
 }
 
@@ -58,6 +63,31 @@ func NewManagedReferencesFromTuples(ctx context.Context, tuples ...any) *Managed
 	return NewManagedReferences(ctx, tuples)
 }
 
+// SetEvents sets a dispatcher of lifecycle events to all decorators in the chain.
+// The dispatcher discovers listeners among components in these references.
+//	Parameters:
+//		- ctx context.Context
+//		- events *LifecycleEvents a dispatcher of lifecycle events.
+func (c *ManagedReferences) SetEvents(ctx context.Context, events *LifecycleEvents) {
+	c.ReferencesDecorator.Events = events
+	c.Fallback.Events = events
+	c.Builder.Events = events
+	c.Linker.Events = events
+	c.Runner.Events = events
+
+	if events != nil {
+		events.SetReferences(ctx, c)
+	}
+}
+
+// SetParent sets parent references to search components that are missing in these references.
+// Components of the parent are shared, but their lifecycle is managed by the parent.
+//	Parameters:
+//		- parent crefer.IReferences parent references or nil to remove the parent.
+func (c *ManagedReferences) SetParent(parent crefer.IReferences) {
+	c.Fallback.SetParent(parent)
+}
+
 // IsOpen checks if the component is opened.
 //	Returns: bool true if the component has been opened and false otherwise.
 func (c *ManagedReferences) IsOpen() bool {
@@ -0,0 +1,348 @@
+package refer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+)
+
+// GraphFormat format of the exported reference graph.
+type GraphFormat string
+
+//	Graph formats
+//	GraphFormatDot: Graphviz DOT language
+//	GraphFormatMermaid: Mermaid flowchart
+const (
+	GraphFormatDot     GraphFormat = "dot"
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+// ReferenceLookup describes how a component looked up other components in the references.
+type ReferenceLookup struct {
+	Consumer any
+	Locator  any
+	Required bool
+	Resolved []any
+}
+
+// ReferenceGraph records lookups that components make through the references
+// they receive in SetReferences and exports them as a graph.
+// Edges go from a component to components it found, lookups that found nothing
+// go to the requested locator and are highlighted.
+//	see LinkReferencesDecorator
+//
+//	Example:
+//		graph := NewReferenceGraph()
+//		references.Linker.Graph = graph
+//		references.Open(ctx, "123")
+//		fmt.Println(graph.ToDot())
+type ReferenceGraph struct {
+	lock    sync.Mutex
+	lookups []*ReferenceLookup
+}
+
+// NewReferenceGraph creates a new empty instance of the reference graph.
+//	Returns: *ReferenceGraph
+func NewReferenceGraph() *ReferenceGraph {
+	return &ReferenceGraph{
+		lookups: make([]*ReferenceLookup, 0),
+	}
+}
+
+// Record records a lookup made by a component.
+// Repeated lookups are recorded once, a lookup is required when any of them was required.
+//	Parameters:
+//		- consumer any a locator of the component that made the lookup.
+//		- locator any the requested locator.
+//		- required bool true when the reference was required.
+//		- resolved []any locators of found components.
+func (c *ReferenceGraph) Record(consumer any, locator any, required bool, resolved []any) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, lookup := range c.lookups {
+		if lookupKey(lookup.Consumer) == lookupKey(consumer) && lookupKey(lookup.Locator) == lookupKey(locator) {
+			lookup.Required = lookup.Required || required
+			for _, other := range resolved {
+				if !containsLocator(lookup.Resolved, other) {
+					lookup.Resolved = append(lookup.Resolved, other)
+				}
+			}
+			return
+		}
+	}
+
+	c.lookups = append(c.lookups, &ReferenceLookup{
+		Consumer: consumer,
+		Locator:  locator,
+		Required: required,
+		Resolved: append([]any{}, resolved...),
+	})
+}
+
+// GetLookups gets recorded lookups.
+//	Returns: []*ReferenceLookup lookups in the order they were made.
+func (c *ReferenceGraph) GetLookups() []*ReferenceLookup {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	lookups := make([]*ReferenceLookup, len(c.lookups))
+	for index, lookup := range c.lookups {
+		copied := *lookup
+		copied.Resolved = append([]any{}, lookup.Resolved...)
+		lookups[index] = &copied
+	}
+	return lookups
+}
+
+// Clear removes all recorded lookups.
+func (c *ReferenceGraph) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lookups = make([]*ReferenceLookup, 0)
+}
+
+// Export exports the graph in the specified format.
+//	Parameters:
+//		- format GraphFormat a format of the graph.
+//	Returns: string, error the graph and ConfigError when the format is not supported.
+func (c *ReferenceGraph) Export(format GraphFormat) (string, error) {
+	switch format {
+	case GraphFormatDot, "":
+		return c.ToDot(), nil
+	case GraphFormatMermaid:
+		return c.ToMermaid(), nil
+	default:
+		return "", cerr.NewConfigError(
+			"", "UNSUPPORTED_FORMAT", "Unsupported graph format "+string(format),
+		).WithDetails("format", format)
+	}
+}
+
+// ToDot exports the graph in Graphviz DOT language.
+// Optional references are dashed, lookups that found nothing are red.
+//	Returns: string the graph definition.
+func (c *ReferenceGraph) ToDot() string {
+	nodes, edges := c.buildGraph()
+
+	builder := strings.Builder{}
+	builder.WriteString("digraph references {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	for index, node := range nodes {
+		attributes := ""
+		if node.missing {
+			attributes = ", style=dashed, color=red, fontcolor=red"
+		}
+		builder.WriteString(fmt.Sprintf("  n%d [label=\"%s\"%s];\n", index, escapeDot(node.label), attributes))
+	}
+	for _, edge := range edges {
+		attributes := "label=\"required\""
+		if !edge.required {
+			attributes = "label=\"optional\", style=dashed"
+		}
+		if edge.missing {
+			attributes += ", color=red, fontcolor=red"
+		}
+		builder.WriteString(fmt.Sprintf("  n%d -> n%d [%s];\n", edge.from, edge.to, attributes))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// ToMermaid exports the graph as Mermaid flowchart.
+// Optional references are dotted, lookups that found nothing are red.
+//	Returns: string the graph definition.
+func (c *ReferenceGraph) ToMermaid() string {
+	nodes, edges := c.buildGraph()
+
+	builder := strings.Builder{}
+	builder.WriteString("graph LR\n")
+	missing := make([]string, 0)
+	for index, node := range nodes {
+		builder.WriteString(fmt.Sprintf("  n%d[\"%s\"]\n", index, escapeMermaid(node.label)))
+		if node.missing {
+			missing = append(missing, fmt.Sprintf("n%d", index))
+		}
+	}
+	for _, edge := range edges {
+		if edge.required {
+			builder.WriteString(fmt.Sprintf("  n%d -->|required| n%d\n", edge.from, edge.to))
+		} else {
+			builder.WriteString(fmt.Sprintf("  n%d -.->|optional| n%d\n", edge.from, edge.to))
+		}
+	}
+	if len(missing) > 0 {
+		builder.WriteString("  classDef missing stroke:#f00,color:#f00,stroke-dasharray:5 5\n")
+		builder.WriteString("  class " + strings.Join(missing, ",") + " missing\n")
+	}
+	return builder.String()
+}
+
+type graphNode struct {
+	label   string
+	missing bool
+}
+
+type graphEdge struct {
+	from     int
+	to       int
+	required bool
+	missing  bool
+}
+
+func (c *ReferenceGraph) buildGraph() ([]*graphNode, []*graphEdge) {
+	nodes := make([]*graphNode, 0)
+	indexes := make(map[string]int)
+	getNode := func(label string, missing bool) int {
+		key := fmt.Sprintf("%s|%v", label, missing)
+		if index, ok := indexes[key]; ok {
+			return index
+		}
+		nodes = append(nodes, &graphNode{label: label, missing: missing})
+		indexes[key] = len(nodes) - 1
+		return len(nodes) - 1
+	}
+
+	edges := make([]*graphEdge, 0)
+	addEdge := func(edge *graphEdge) {
+		for _, other := range edges {
+			if other.from == edge.from && other.to == edge.to {
+				other.required = other.required || edge.required
+				return
+			}
+		}
+		edges = append(edges, edge)
+	}
+
+	for _, lookup := range c.GetLookups() {
+		from := getNode(lookupKey(lookup.Consumer), false)
+		if len(lookup.Resolved) == 0 {
+			to := getNode(lookupKey(lookup.Locator), true)
+			addEdge(&graphEdge{from: from, to: to, required: lookup.Required, missing: true})
+			continue
+		}
+
+		resolved := make([]string, 0, len(lookup.Resolved))
+		for _, locator := range lookup.Resolved {
+			resolved = append(resolved, lookupKey(locator))
+		}
+		sort.Strings(resolved)
+		for _, label := range resolved {
+			addEdge(&graphEdge{from: from, to: getNode(label, false), required: lookup.Required})
+		}
+	}
+
+	return nodes, edges
+}
+
+func lookupKey(locator any) string {
+	return fmt.Sprintf("%v", locator)
+}
+
+func containsLocator(locators []any, locator any) bool {
+	for _, other := range locators {
+		if lookupKey(other) == lookupKey(locator) {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeDot(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "\\", "\\\\"), "\"", "\\\"")
+}
+
+func escapeMermaid(value string) string {
+	return strings.ReplaceAll(value, "\"", "#quot;")
+}
+
+// trackingReferences passes lookups of a component to the references
+// and records them in the reference graph.
+type trackingReferences struct {
+	*ReferencesDecorator
+	graph    *ReferenceGraph
+	consumer any
+}
+
+func newTrackingReferences(references crefer.IReferences, graph *ReferenceGraph,
+	consumer any) *trackingReferences {
+
+	return &trackingReferences{
+		ReferencesDecorator: NewReferencesDecorator(references, references),
+		graph:               graph,
+		consumer:            consumer,
+	}
+}
+
+func (c *trackingReferences) record(locator any, required bool, components []any) {
+	resolved := make([]any, 0, len(components))
+	for _, component := range components {
+		resolved = append(resolved, c.getLocator(component))
+	}
+	c.graph.Record(c.consumer, locator, required, resolved)
+}
+
+// GetOneOptional gets an optional component reference that matches specified locator.
+//	Parameters:
+//		- locator any a locator to find a reference by.
+//	Returns: any a matching component reference or nil if nothing was found.
+func (c *trackingReferences) GetOneOptional(locator any) any {
+	component := c.NextReferences.GetOneOptional(locator)
+	if component == nil {
+		c.record(locator, false, nil)
+	} else {
+		c.record(locator, false, []any{component})
+	}
+	return component
+}
+
+// GetOneRequired gets a required component reference that matches specified locator.
+//	Parameters:
+//		- locator any a locator to find a reference by.
+//	Returns: any, error a matching component reference, a ReferenceError when no references found.
+func (c *trackingReferences) GetOneRequired(locator any) (any, error) {
+	component, err := c.NextReferences.GetOneRequired(locator)
+	if err != nil || component == nil {
+		c.record(locator, true, nil)
+	} else {
+		c.record(locator, true, []any{component})
+	}
+	return component, err
+}
+
+// GetOptional gets all component references that match specified locator.
+//	Parameters:
+//		- locator any a locator to find references by.
+//	Returns: []any a list with matching component references or empty list if nothing was found.
+func (c *trackingReferences) GetOptional(locator any) []any {
+	components := c.NextReferences.GetOptional(locator)
+	c.record(locator, false, components)
+	return components
+}
+
+// GetRequired gets all component references that match specified locator.
+//	Parameters:
+//		- locator any a locator to find references by.
+//	Returns: []any, error a list with matching component references and
+//		a ReferenceError when no references found.
+func (c *trackingReferences) GetRequired(locator any) ([]any, error) {
+	components, err := c.NextReferences.GetRequired(locator)
+	c.record(locator, true, components)
+	return components, err
+}
+
+// Find finds all component references that match specified locator.
+//	Parameters:
+//		- locator any the locator to find a reference by.
+//		- required bool forces to raise an exception if no reference is found.
+//	Returns: []any, error a list with matching component references and
+//		a ReferenceError when required is set to true but no references found
+func (c *trackingReferences) Find(locator any, required bool) ([]any, error) {
+	components, err := c.NextReferences.Find(locator, required)
+	c.record(locator, required, components)
+	return components, err
+}
@@ -2,6 +2,7 @@ package refer
 
 import (
 	"context"
+	"fmt"
 
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 )
@@ -9,9 +10,11 @@ import (
 // ReferencesDecorator chainable decorator for IReferences that allows
 // to inject additional capabilities such as
 // automatic component creation, automatic registration and opening.
+// Decorators send component lifecycle events to Events dispatcher when it is set.
 type ReferencesDecorator struct {
 	NextReferences crefer.IReferences
 	TopReferences  crefer.IReferences
+	Events         *LifecycleEvents
 }
 
 // NewReferencesDecorator creates a new instance of the decorator.
@@ -145,3 +148,15 @@ func (c *ReferencesDecorator) GetRequired(locator any) ([]any, error) {
 func (c *ReferencesDecorator) Find(locator any, required bool) ([]any, error) {
 	return c.NextReferences.Find(locator, required)
 }
+
+// getLocator finds a locator of the component in the references.
+// When the component is not found it returns the component type name.
+func (c *ReferencesDecorator) getLocator(component any) any {
+	locators := c.GetAllLocators()
+	for index, other := range c.GetAll() {
+		if other == component && index < len(locators) {
+			return locators[index]
+		}
+	}
+	return fmt.Sprintf("%T", component)
+}
@@ -2,7 +2,11 @@ package refer
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	crefer "github.com/pip-services3-gox/pip-services3-commons-gox/refer"
 	"github.com/pip-services3-gox/pip-services3-commons-gox/run"
 )
@@ -10,9 +14,40 @@ import (
 // RunReferencesDecorator References decorator that automatically opens
 // to newly added components that implement IOpenable interface and
 // closes removed components that implement ICloseable interface.
+// Components are opened in the order of their declared dependencies
+// and closed in the reverse order. In parallel mode components
+// that do not depend on each other are opened and closed concurrently.
+// Timeouts to open and close components are passed to them as context deadlines.
+// When a component does not complete in time the decorator stops waiting for it
+// and returns an error with the component locator. A component that completes
+// opening after the timeout is closed, since it is not tracked as opened.
+// Components with a restart policy are restarted after they report runtime failures.
+//	see DependencyGraph
+//	see SetComponentRestartPolicy
 type RunReferencesDecorator struct {
 	*ReferencesDecorator
-	opened bool
+	Dependencies   *DependencyGraph
+	lock           sync.Mutex
+	opened         bool
+	parallel       bool
+	maxConcurrency int
+	openTimeout    time.Duration
+	closeTimeout   time.Duration
+	timeouts       []*componentTimeouts
+	// Components successfully opened in the order they were opened and the opened flag.
+	// They are changed under both locks and read under any of them.
+	openedComponents []any
+	openedLock       sync.Mutex
+	// Context the references were opened with to report failures and restart components
+	runCtx          context.Context
+	supervisorsLock sync.Mutex
+	supervisors     []*componentSupervisor
+}
+
+type componentTimeouts struct {
+	component    any
+	openTimeout  time.Duration
+	closeTimeout time.Duration
 }
 
 // NewRunReferencesDecorator creates a new instance of the decorator.
@@ -25,42 +60,529 @@ func NewRunReferencesDecorator(nextReferences crefer.IReferences,
 
 	return &RunReferencesDecorator{
 		ReferencesDecorator: NewReferencesDecorator(nextReferences, topReferences),
+		Dependencies:        NewDependencyGraph(),
 	}
 }
 
 // IsOpen checks if the component is opened.
 //	Returns: bool true if the component has been opened and false otherwise.
 func (c *RunReferencesDecorator) IsOpen() bool {
+	c.openedLock.Lock()
+	defer c.openedLock.Unlock()
 	return c.opened
 }
 
+// IsComponentOpen checks if a component was opened by the references and was not closed since,
+// for instance to restart it after a failure. It does not wait for components being opened or closed.
+//	Parameters:
+//		- component any a component to check.
+//	Returns: bool true if the component is opened and false otherwise.
+func (c *RunReferencesDecorator) IsComponentOpen(component any) bool {
+	c.openedLock.Lock()
+	defer c.openedLock.Unlock()
+	return c.isOpened(component)
+}
+
+// IsParallel checks if components are opened and closed in parallel.
+//	Returns: bool true if parallel mode is enabled and false otherwise.
+func (c *RunReferencesDecorator) IsParallel() bool {
+	return c.parallel
+}
+
+// SetParallel enables or disables parallel mode. In parallel mode
+// components that do not depend on each other are opened and closed concurrently.
+//	Parameters:
+//		- parallel bool true to enable parallel mode.
+//		- maxConcurrency int maximum number of components opened
+//			or closed at the same time or 0 for unlimited.
+func (c *RunReferencesDecorator) SetParallel(parallel bool, maxConcurrency int) {
+	c.parallel = parallel
+	c.maxConcurrency = maxConcurrency
+}
+
+// SetTimeouts sets default timeouts to open and close components.
+//	Parameters:
+//		- openTimeout time.Duration timeout to open a component or 0 for no timeout.
+//		- closeTimeout time.Duration timeout to close a component or 0 for no timeout.
+func (c *RunReferencesDecorator) SetTimeouts(openTimeout time.Duration, closeTimeout time.Duration) {
+	c.openTimeout = openTimeout
+	c.closeTimeout = closeTimeout
+}
+
+// SetComponentTimeouts sets timeouts to open and close a specific component.
+// They override the default timeouts.
+//	see SetTimeouts
+//	Parameters:
+//		- component any a component to set timeouts for.
+//		- openTimeout time.Duration timeout to open the component or 0 to use the default.
+//		- closeTimeout time.Duration timeout to close the component or 0 to use the default.
+func (c *RunReferencesDecorator) SetComponentTimeouts(component any,
+	openTimeout time.Duration, closeTimeout time.Duration) {
+
+	c.removeTimeouts(component)
+	if openTimeout > 0 || closeTimeout > 0 {
+		c.timeouts = append(c.timeouts, &componentTimeouts{
+			component:    component,
+			openTimeout:  openTimeout,
+			closeTimeout: closeTimeout,
+		})
+	}
+}
+
+func (c *RunReferencesDecorator) removeTimeouts(component any) {
+	for index, timeouts := range c.timeouts {
+		if timeouts.component == component {
+			c.timeouts = append(c.timeouts[:index], c.timeouts[index+1:]...)
+			return
+		}
+	}
+}
+
+func (c *RunReferencesDecorator) getTimeouts(component any) (openTimeout time.Duration, closeTimeout time.Duration) {
+	openTimeout, closeTimeout = c.openTimeout, c.closeTimeout
+	for _, timeouts := range c.timeouts {
+		if timeouts.component == component {
+			if timeouts.openTimeout > 0 {
+				openTimeout = timeouts.openTimeout
+			}
+			if timeouts.closeTimeout > 0 {
+				closeTimeout = timeouts.closeTimeout
+			}
+			break
+		}
+	}
+	return openTimeout, closeTimeout
+}
+
+// openOne opens a single component within its open timeout.
+func (c *RunReferencesDecorator) openOne(ctx context.Context, correlationId string, component any) error {
+	return c.openOneOrAbort(ctx, correlationId, component, nil)
+}
+
+// openOneOrAbort opens a single component and stops waiting for it when abort is closed.
+// The component keeps its context, and if it completes opening later, it is closed.
+func (c *RunReferencesDecorator) openOneOrAbort(ctx context.Context, correlationId string,
+	component any, abort <-chan struct{}) error {
+
+	if _, ok := component.(run.IOpenable); !ok {
+		return nil
+	}
+
+	ctx = c.superviseContext(ctx, component)
+
+	start := time.Now()
+	timeout, _ := c.getTimeouts(component)
+	err := c.runWithTimeout(ctx, correlationId, component, timeout, abort, "OPEN_TIMEOUT", "open",
+		func(ctx context.Context, component any) error {
+			return run.Opener.OpenOne(ctx, correlationId, component)
+		},
+		func(err error) {
+			if err == nil {
+				_ = c.closeOne(context.Background(), correlationId, component)
+			}
+		},
+	)
+
+	c.Events.NotifyStep(ctx, correlationId, ComponentOpened, c.getLocator(component), component, start, err)
+	return err
+}
+
+// closeOne closes a single component within its close timeout.
+func (c *RunReferencesDecorator) closeOne(ctx context.Context, correlationId string, component any) error {
+	if _, ok := component.(run.IClosable); !ok {
+		return nil
+	}
+
+	start := time.Now()
+	_, timeout := c.getTimeouts(component)
+	err := c.runWithTimeout(ctx, correlationId, component, timeout, nil, "CLOSE_TIMEOUT", "close",
+		func(ctx context.Context, component any) error {
+			return run.Closer.CloseOne(ctx, correlationId, component)
+		},
+		nil,
+	)
+
+	c.Events.NotifyStep(ctx, correlationId, ComponentClosed, c.getLocator(component), component, start, err)
+	return err
+}
+
+// runWithTimeout runs the action with the timeout passed to the component as the context deadline.
+// The context is cancelled only when the action does not complete in time, so components
+// may keep using it after they are opened. When abort is closed, the decorator stops waiting
+// without cancelling the context. When the decorator stops waiting,
+// the late result of the action is passed to completedLate.
+func (c *RunReferencesDecorator) runWithTimeout(ctx context.Context, correlationId string,
+	component any, timeout time.Duration, abort <-chan struct{}, code string, operation string,
+	action func(ctx context.Context, component any) error, completedLate func(err error)) error {
+
+	if timeout <= 0 && abort == nil {
+		return action(ctx, component)
+	}
+
+	cancel := func(expired bool) {}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		ctx, cancel = newDeadlineContext(ctx, time.Now().Add(timeout))
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// Buffered, so a hung component does not leak the goroutine forever after it returns
+	done := make(chan error, 1)
+	go func() {
+		done <- runAction(ctx, action, component)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		err = ctx.Err()
+		cancel(false)
+	case <-abort:
+		locator := c.getLocator(component)
+		err = cerr.NewInvalidStateError(
+			correlationId,
+			"OPEN_ABORTED",
+			fmt.Sprintf("Stopped waiting for component %v to %s after another component failed", locator, operation),
+		).WithDetails("locator", fmt.Sprintf("%v", locator))
+	case <-expired:
+		cancel(true)
+		locator := c.getLocator(component)
+		err = cerr.NewInvalidStateError(
+			correlationId,
+			code,
+			fmt.Sprintf("Component %v failed to %s in %v", locator, operation, timeout),
+		).WithDetails("locator", fmt.Sprintf("%v", locator)).
+			WithDetails("timeout", timeout.Milliseconds())
+	}
+
+	if completedLate != nil {
+		go func() {
+			completedLate(<-done)
+		}()
+	}
+	return err
+}
+
+// deadlineContext reports a deadline to a component without cancelling
+// the context when the deadline passes. It is cancelled explicitly.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+	lock     sync.Mutex
+	expired  bool
+}
+
+// newDeadlineContext creates a context with the deadline and a function that cancels it.
+// After it is cancelled as expired, the context reports context.DeadlineExceeded error.
+func newDeadlineContext(parent context.Context, deadline time.Time) (*deadlineContext, func(expired bool)) {
+	ctx, cancel := context.WithCancel(parent)
+	result := &deadlineContext{Context: ctx, deadline: deadline}
+	return result, func(expired bool) {
+		result.lock.Lock()
+		result.expired = expired
+		result.lock.Unlock()
+		cancel()
+	}
+}
+
+// Deadline gets the earliest of the context deadline and the parent deadline.
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	if deadline, ok := c.Context.Deadline(); ok && deadline.Before(c.deadline) {
+		return deadline, true
+	}
+	return c.deadline, true
+}
+
+// Err gets context.DeadlineExceeded when the context was cancelled because of the timeout.
+func (c *deadlineContext) Err() error {
+	err := c.Context.Err()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil && c.expired {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// detachedContext keeps values of the parent context, but is never cancelled.
+// It lets components close within their close timeouts after the open is cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func newDetachedContext(parent context.Context) context.Context {
+	return detachedContext{Context: parent}
+}
+
+func (c detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
 // Open the component.
+// When one of components fails to open, the components opened before
+// are closed in the reverse order and OpenError is returned.
+//	see OpenError
 //	Parameters:
 //		- ctx context.Context
 //		- correlationId string transaction id to trace execution through call chain.
 //	Returns: error
 func (c *RunReferencesDecorator) Open(ctx context.Context, correlationId string) error {
-	if !c.opened {
-		components := c.GetAll()
-		err := run.Opener.Open(ctx, correlationId, components)
-		c.opened = err == nil
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.opened {
+		return nil
+	}
+
+	c.runCtx = ctx
+
+	var err error
+	if c.parallel {
+		err = c.openParallel(ctx, correlationId)
+	} else {
+		err = c.openSequential(ctx, correlationId)
+	}
+
+	if componentErr, ok := err.(*ComponentError); ok {
+		c.stopSupervisors()
+		return c.rollback(ctx, correlationId, componentErr)
+	}
+
+	c.setOpened(err == nil)
+	if err != nil {
+		c.stopSupervisors()
+	}
+	return err
+}
+
+func (c *RunReferencesDecorator) openSequential(ctx context.Context, correlationId string) error {
+	components, err := c.Dependencies.Sort(correlationId, c)
+	if err != nil {
 		return err
 	}
+
+	for _, component := range components {
+		// Components may return without an error after the context is cancelled
+		if ctx.Err() != nil {
+			locator := c.getLocator(component)
+			err = cerr.NewInvalidStateError(
+				correlationId,
+				"OPEN_CANCELLED",
+				fmt.Sprintf("Open was cancelled before component %v", locator),
+			).WithDetails("locator", fmt.Sprintf("%v", locator)).
+				WithCause(ctx.Err())
+			return NewComponentError(locator, err)
+		}
+		if err = c.openOne(ctx, correlationId, component); err != nil {
+			return NewComponentError(c.getLocator(component), err)
+		}
+		c.addOpened(component)
+	}
 	return nil
 }
 
+func (c *RunReferencesDecorator) openParallel(ctx context.Context, correlationId string) error {
+	// Stops waiting for components being opened after the first failure.
+	// Their context is not cancelled, since opened components may keep it.
+	abort := make(chan struct{})
+	var abortOnce sync.Once
+
+	opened, err := c.Dependencies.ForEachParallel(ctx, correlationId, c, c.maxConcurrency, false,
+		func(ctx context.Context, component any) error {
+			if err := c.openOneOrAbort(ctx, correlationId, component, abort); err != nil {
+				abortOnce.Do(func() { close(abort) })
+				return NewComponentError(c.getLocator(component), err)
+			}
+			return nil
+		},
+	)
+
+	// Components complete in the dependency order, so they can be closed in the reverse order
+	c.addOpened(opened...)
+	return err
+}
+
+// rollback closes components opened before the failure in the reverse order.
+// The open context may be cancelled, so components are closed with a detached one.
+func (c *RunReferencesDecorator) rollback(ctx context.Context, correlationId string,
+	cause *ComponentError) error {
+
+	ctx = newDetachedContext(ctx)
+	closeErrors := make([]*ComponentError, 0)
+	for _, component := range reverseComponents(c.openedComponents) {
+		if err := c.closeOne(ctx, correlationId, component); err != nil {
+			closeErrors = append(closeErrors, NewComponentError(c.getLocator(component), err))
+		}
+	}
+	c.clearOpened()
+
+	return NewOpenError(cause, closeErrors)
+}
+
 // Close component and frees used resources.
+// Only components that were successfully opened are closed.
 //	Parameters:
 //		- ctx context.Context
 //		- correlationId string transaction id to trace execution through call chain.
 //	Returns: error
 func (c *RunReferencesDecorator) Close(ctx context.Context, correlationId string) error {
-	components := c.GetAll()
-	err := run.Closer.Close(ctx, correlationId, components)
-	c.opened = false
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopSupervisors()
+
+	var err error
+	if c.parallel {
+		err = c.closeParallel(ctx, correlationId)
+	} else {
+		err = c.closeSequential(ctx, correlationId)
+	}
+	c.clearOpened()
+	c.setOpened(false)
+	c.runCtx = nil
 	return err
 }
 
+func (c *RunReferencesDecorator) closeSequential(ctx context.Context, correlationId string) error {
+	// Close all components even when some of them fail
+	var closeErr error
+	for _, component := range reverseComponents(c.openedComponents) {
+		if err := c.closeOne(ctx, correlationId, component); err != nil && closeErr == nil {
+			closeErr = NewComponentError(c.getLocator(component), err)
+		}
+	}
+	return closeErr
+}
+
+func (c *RunReferencesDecorator) closeParallel(ctx context.Context, correlationId string) error {
+	var lock sync.Mutex
+	var closeErr error
+
+	// Close all components even when some of them fail
+	_, err := c.Dependencies.ForEachParallel(ctx, correlationId, c, c.maxConcurrency, true,
+		func(ctx context.Context, component any) error {
+			if !c.isOpened(component) {
+				return nil
+			}
+			if err := c.closeOne(ctx, correlationId, component); err != nil {
+				lock.Lock()
+				if closeErr == nil {
+					closeErr = NewComponentError(c.getLocator(component), err)
+				}
+				lock.Unlock()
+			}
+			return nil
+		},
+	)
+
+	if err != nil {
+		return c.closeSequential(ctx, correlationId)
+	}
+
+	return closeErr
+}
+
+// updateReferences changes references, dependencies or timeouts of components under the lock,
+// so restarts of supervised components do not read them while they are changed.
+func (c *RunReferencesDecorator) updateReferences(action func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	action()
+}
+
+// openComponent opens a component added after the references were opened.
+// It does nothing when the references are not opened or the component is already opened.
+func (c *RunReferencesDecorator) openComponent(ctx context.Context, correlationId string, component any) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.opened || c.isOpened(component) {
+		return nil
+	}
+	return c.openComponentLocked(ctx, correlationId, component)
+}
+
+// openComponentLocked opens a single component. The caller must hold the lock.
+func (c *RunReferencesDecorator) openComponentLocked(ctx context.Context, correlationId string, component any) error {
+	if err := c.openOne(ctx, correlationId, component); err != nil {
+		return NewComponentError(c.getLocator(component), err)
+	}
+	c.addOpened(component)
+	return nil
+}
+
+// closeComponent closes a single component if it was opened.
+func (c *RunReferencesDecorator) closeComponent(ctx context.Context, correlationId string, component any) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.closeComponentLocked(ctx, correlationId, component)
+}
+
+// closeComponentLocked closes a single component if it was opened. The caller must hold the lock.
+func (c *RunReferencesDecorator) closeComponentLocked(ctx context.Context, correlationId string, component any) error {
+	if !c.isOpened(component) {
+		return nil
+	}
+
+	c.removeOpened(component)
+	if err := c.closeOne(ctx, correlationId, component); err != nil {
+		return NewComponentError(c.getLocator(component), err)
+	}
+	return nil
+}
+
+func (c *RunReferencesDecorator) isOpened(component any) bool {
+	for _, opened := range c.openedComponents {
+		if opened == component {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *RunReferencesDecorator) addOpened(components ...any) {
+	c.openedLock.Lock()
+	defer c.openedLock.Unlock()
+	c.openedComponents = append(c.openedComponents, components...)
+}
+
+func (c *RunReferencesDecorator) setOpened(opened bool) {
+	c.openedLock.Lock()
+	defer c.openedLock.Unlock()
+	c.opened = opened
+}
+
+func (c *RunReferencesDecorator) clearOpened() {
+	c.openedLock.Lock()
+	defer c.openedLock.Unlock()
+	c.openedComponents = nil
+}
+
+func (c *RunReferencesDecorator) removeOpened(component any) {
+	c.openedLock.Lock()
+	defer c.openedLock.Unlock()
+	for index, opened := range c.openedComponents {
+		if opened == component {
+			c.openedComponents = append(c.openedComponents[:index], c.openedComponents[index+1:]...)
+			return
+		}
+	}
+}
+
 // Put a new reference into this reference map.
 //	Parameters:
 //		- ctx context.Context
@@ -68,10 +590,7 @@ func (c *RunReferencesDecorator) Close(ctx context.Context, correlationId string
 //		- component any a component reference to be added.
 func (c *RunReferencesDecorator) Put(ctx context.Context, locator any, component any) {
 	c.ReferencesDecorator.Put(ctx, locator, component)
-
-	if c.opened {
-		_ = run.Opener.OpenOne(ctx, "", component)
-	}
+	_ = c.openComponent(ctx, "", component)
 }
 
 // Remove a previously added reference that matches specified locator.
@@ -83,12 +602,11 @@ func (c *RunReferencesDecorator) Put(ctx context.Context, locator any, component
 //		- locator any the locator to remove references by.
 //	Returns: any the removed component reference.
 func (c *RunReferencesDecorator) Remove(ctx context.Context, locator any) any {
-	component := c.ReferencesDecorator.Remove(ctx, locator)
-
-	if c.opened {
-		_ = run.Closer.CloseOne(ctx, "", component)
-	}
-
+	var component any
+	c.updateReferences(func() {
+		component = c.ReferencesDecorator.Remove(ctx, locator)
+		c.removeComponentLocked(ctx, component)
+	})
 	return component
 }
 
@@ -98,11 +616,29 @@ func (c *RunReferencesDecorator) Remove(ctx context.Context, locator any) any {
 //		- locator any the locator to remove references by.
 //	Returns: []any a list, containing all removed references.
 func (c *RunReferencesDecorator) RemoveAll(ctx context.Context, locator any) []any {
-	components := c.NextReferences.RemoveAll(ctx, locator)
+	var components []any
+	c.updateReferences(func() {
+		components = c.NextReferences.RemoveAll(ctx, locator)
+		for _, component := range components {
+			c.removeComponentLocked(ctx, component)
+		}
+	})
+	return components
+}
 
-	if c.opened {
-		_ = run.Closer.Close(ctx, "", components)
-	}
+// removeComponentLocked stops supervising and closes a removed component,
+// and forgets its dependencies and timeouts. The caller must hold the lock.
+func (c *RunReferencesDecorator) removeComponentLocked(ctx context.Context, component any) {
+	c.removeSupervisor(component)
+	_ = c.closeComponentLocked(ctx, "", component)
+	c.Dependencies.RemoveDependencies(component)
+	c.removeTimeouts(component)
+}
 
-	return components
+func reverseComponents(components []any) []any {
+	reversed := make([]any, len(components))
+	for index, component := range components {
+		reversed[len(components)-index-1] = component
+	}
+	return reversed
 }
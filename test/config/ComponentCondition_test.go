@@ -0,0 +1,66 @@
+package test_config
+
+import (
+	"testing"
+
+	conf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cconf "github.com/pip-services3-gox/pip-services3-container-gox/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	parameters := conf.NewConfigParamsFromTuples(
+		"ENV", "prod",
+		"DEBUG", "false",
+		"METRICS", "true",
+		"REGION", "us-east-1",
+	)
+
+	conditions := map[string]bool{
+		"":                              true,
+		"true":                          true,
+		"false":                         false,
+		"ENV == prod":                   true,
+		"ENV == 'dev'":                  false,
+		"ENV != \"dev\"":                true,
+		"METRICS":                       true,
+		"DEBUG":                         false,
+		"MISSING":                       false,
+		"!MISSING":                      true,
+		"REGION":                        true,
+		"ENV == prod && !DEBUG":         true,
+		"ENV == dev || METRICS":         true,
+		"!(ENV == prod || ENV == test)": false,
+		"(ENV == dev || ENV == prod) && METRICS && REGION == us-east-1": true,
+	}
+
+	for condition, expected := range conditions {
+		result, err := cconf.EvaluateCondition(condition, parameters)
+		assert.Nil(t, err, condition)
+		assert.Equal(t, expected, result, condition)
+	}
+
+	for _, condition := range []string{"ENV ==", "(ENV == prod", "ENV = prod", "&& ENV", "'prod"} {
+		_, err := cconf.EvaluateCondition(condition, parameters)
+		assert.NotNil(t, err, condition)
+	}
+}
+
+func TestComponentConfigCondition(t *testing.T) {
+	config := conf.NewConfigParamsFromTuples(
+		"descriptor", "group:type:kind:name:version",
+		"enabled", "METRICS",
+		"if", "ENV == prod",
+	)
+	componentConfig, err := cconf.ReadComponentConfigFromConfig(config)
+	assert.Nil(t, err)
+
+	enabled, err := componentConfig.IsEnabled(conf.NewConfigParamsFromTuples("ENV", "prod", "METRICS", "true"))
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = componentConfig.IsEnabled(conf.NewConfigParamsFromTuples("ENV", "prod"))
+	assert.Nil(t, err)
+	assert.False(t, enabled)
+}
@@ -2,6 +2,7 @@ package test_config
 
 import (
 	"testing"
+	"time"
 
 	conf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
 	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
@@ -78,3 +79,41 @@ func TestComponentConfigFromCorrectConfig(t *testing.T) {
 	assert.Equal(t, "name", componentConfig.Descriptor.Name())
 	assert.Equal(t, "version", componentConfig.Descriptor.Version())
 }
+
+func TestComponentConfigTimeouts(t *testing.T) {
+	config := conf.NewConfigParamsFromTuples(
+		"descriptor", "group:type:kind:name:version",
+		"open_timeout", 5000,
+		"close_timeout", 1000,
+	)
+	componentConfig, err := cconf.ReadComponentConfigFromConfig(config)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, componentConfig.OpenTimeout)
+	assert.Equal(t, time.Second, componentConfig.CloseTimeout)
+}
+
+func TestComponentConfigRestartPolicy(t *testing.T) {
+	config := conf.NewConfigParamsFromTuples(
+		"descriptor", "group:type:kind:name:version",
+		"restart.policy", "on_failure",
+		"restart.max_restarts", 5,
+		"restart.backoff", 100,
+		"restart.max_backoff", 300,
+	)
+	componentConfig, err := cconf.ReadComponentConfigFromConfig(config)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, componentConfig.Restart)
+	assert.Equal(t, cconf.RestartOnFailure, componentConfig.Restart.Mode)
+	assert.Equal(t, 5, componentConfig.Restart.MaxRestarts)
+	assert.Equal(t, 100*time.Millisecond, componentConfig.Restart.GetDelay(0))
+	assert.Equal(t, 200*time.Millisecond, componentConfig.Restart.GetDelay(1))
+	assert.Equal(t, 300*time.Millisecond, componentConfig.Restart.GetDelay(2))
+	assert.Equal(t, time.Minute, componentConfig.Restart.ResetAfter)
+
+	// Unknown restart modes are rejected instead of being treated as another mode
+	config.Put("restart.policy", "on-failure")
+	_, err = cconf.ReadComponentConfigFromConfig(config)
+	assert.NotNil(t, err)
+}
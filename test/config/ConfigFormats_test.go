@@ -0,0 +1,91 @@
+package test_config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	conf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cconf "github.com/pip-services3-gox/pip-services3-container-gox/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConfigFormats(t *testing.T) {
+	dir := t.TempDir()
+	parameters := conf.NewConfigParamsFromTuples("LOG_LEVEL", "debug")
+
+	files := map[string]string{
+		"config.toml": `
+# Components
+[[components]]
+descriptor = "pip-services:logger:console:default:1.0"
+level = "{{LOG_LEVEL}}"
+
+[[components]]
+descriptor = "pip-services:counters:log:default:1.0"
+interval = 1000
+dependencies = ["pip-services:logger:*:*:1.0"]
+`,
+		"config.properties": `
+# Components
+components.0.descriptor=pip-services:logger:console:default:1.0
+components.0.level = {{LOG_LEVEL}}
+components.1.descriptor: pip-services:counters:log:default:1.0
+components.1.interval 1000
+components.1.dependencies.0=pip-services:logger:\
+    *:*:1.0
+`,
+		"config.conf": `
+- descriptor: "pip-services:logger:console:default:1.0"
+  level: "{{LOG_LEVEL}}"
+- descriptor: "pip-services:counters:log:default:1.0"
+  interval: 1000
+  dependencies:
+    - "pip-services:logger:*:*:1.0"
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		writeConfigFile(t, path, content)
+
+		config, err := cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, parameters)
+		assert.Nil(t, err, name)
+		if !assert.Len(t, config, 2, name) {
+			continue
+		}
+		assert.Equal(t, "pip-services:logger:console:default:1.0", config[0].Descriptor.String(), name)
+		assert.Equal(t, "debug", config[0].Config.GetAsString("level"), name)
+		assert.Equal(t, "pip-services:counters:log:default:1.0", config[1].Descriptor.String(), name)
+		assert.Equal(t, 1000, config[1].Config.GetAsInteger("interval"), name)
+		assert.Equal(t, "pip-services:logger:*:*:1.0", config[1].Config.GetAsString("dependencies.0"), name)
+	}
+}
+
+func TestDetectConfigFormat(t *testing.T) {
+	formats := map[string]cconf.ConfigFormat{
+		"[\n  {\"descriptor\": \"a:b:c:d:1.0\"}\n]":    cconf.ConfigFormatJson,
+		"{\"logger\": {}}":                             cconf.ConfigFormatJson,
+		"---\n- descriptor: a:b:c:d:1.0":               cconf.ConfigFormatYaml,
+		"# Logger\nlogger:\n  descriptor: a:b:c:d:1.0": cconf.ConfigFormatYaml,
+		"[[components]]\ndescriptor = \"a:b:c:d:1.0\"": cconf.ConfigFormatToml,
+		"[logger]\ndescriptor = \"a:b:c:d:1.0\"":       cconf.ConfigFormatToml,
+		"! Logger\nlogger.descriptor=a:b:c:d:1.0":      cconf.ConfigFormatProperties,
+	}
+	for text, expected := range formats {
+		format, err := cconf.DetectConfigFormat("123", "config", text)
+		assert.Nil(t, err, text)
+		assert.Equal(t, expected, format, text)
+	}
+
+	_, err := cconf.DetectConfigFormat("123", "config.txt", "just some text")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Cannot detect format of config file config.txt")
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfigFile(t, path, "[[components]\n")
+	_, err = cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Failed to parse toml config file")
+}
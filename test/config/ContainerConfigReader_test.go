@@ -0,0 +1,258 @@
+package test_config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	conf "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cconf "github.com/pip-services3-gox/pip-services3-container-gox/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.Nil(t, err)
+}
+
+func TestReadConfigWithProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	writeConfigFile(t, path, `
+- descriptor: "test:logger:console:default:1.0"
+  level: info
+- descriptor: "test:persistence:mongodb:default:1.0"
+  id: persistence
+  connection:
+    host: localhost
+    port: 27017
+- descriptor: "test:counters:prometheus:default:1.0"
+`)
+	writeConfigFile(t, filepath.Join(dir, "config.dev.yml"), `
+- descriptor: "test:logger:console:default:1.0"
+  level: debug
+- id: persistence
+  connection:
+    host: "{{MONGO_HOST}}"
+- descriptor: "test:counters:prometheus:default:1.0"
+  remove: true
+`)
+	writeConfigFile(t, filepath.Join(dir, "config.local.yml"), `
+- descriptor: "test:controller:default:default:1.0"
+`)
+
+	parameters := conf.NewConfigParamsFromTuples("MONGO_HOST", "mongo")
+	config, err := cconf.ContainerConfigReader.ReadFromFileWithProfiles(context.Background(), "123",
+		path, []string{"dev", "missing", "local"}, parameters)
+	assert.Nil(t, err)
+	assert.Len(t, config, 3)
+
+	assert.Equal(t, "debug", config[0].Config.GetAsString("level"))
+
+	// Parameters are deep-merged
+	assert.Equal(t, "test:persistence:mongodb:default:1.0", config[1].Descriptor.String())
+	assert.Equal(t, "mongo", config[1].Config.GetAsString("connection.host"))
+	assert.Equal(t, 27017, config[1].Config.GetAsInteger("connection.port"))
+
+	assert.Equal(t, "test:controller:default:default:1.0", config[2].Descriptor.String())
+}
+
+func TestReadConfigWithProfileIncludes(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "dev"), 0755))
+
+	path := filepath.Join(dir, "config.yml")
+	writeConfigFile(t, path, `
+- descriptor: "test:logger:console:default:1.0"
+  level: info
+- descriptor: "test:persistence:mongodb:default:1.0"
+  id: persistence
+  connection:
+    host: localhost
+    port: 27017
+`)
+	writeConfigFile(t, filepath.Join(dir, "config.dev.yml"), `
+- include: ./dev/persistence.yml
+- descriptor: "test:controller:default:default:1.0"
+`)
+	writeConfigFile(t, filepath.Join(dir, "dev", "persistence.yml"), `
+- id: persistence
+  connection:
+    host: "{{MONGO_HOST}}"
+- descriptor: "test:logger:console:default:1.0"
+  remove: true
+`)
+
+	parameters := conf.NewConfigParamsFromTuples("MONGO_HOST", "mongo")
+	config, err := cconf.ContainerConfigReader.ReadFromFileWithProfiles(context.Background(), "123",
+		path, []string{"dev"}, parameters)
+	assert.Nil(t, err)
+	assert.Len(t, config, 2)
+
+	// Sections of the included file are merged like sections of the overlay
+	assert.Equal(t, "test:persistence:mongodb:default:1.0", config[0].Descriptor.String())
+	assert.Equal(t, "mongo", config[0].Config.GetAsString("connection.host"))
+	assert.Equal(t, 27017, config[0].Config.GetAsInteger("connection.port"))
+	assert.Equal(t, "test:controller:default:default:1.0", config[1].Descriptor.String())
+
+	// Includes cannot be resolved in overlays that were not read from files
+	_, err = cconf.ContainerConfigReader.MergeConfigs(config,
+		conf.NewConfigParamsFromTuples("0.include", "./dev/persistence.yml"))
+	assert.NotNil(t, err)
+}
+
+func TestReadConfigWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "common"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "conf.d"), 0755))
+
+	path := filepath.Join(dir, "config.yml")
+	writeConfigFile(t, path, `
+- include: ./common/logging.yml
+- descriptor: "test:controller:default:default:1.0"
+- include:
+    - ./conf.d/*.yml
+    - ./conf.d/*.json
+- include: ./local.yml
+  optional: true
+`)
+	writeConfigFile(t, filepath.Join(dir, "common", "logging.yml"), `
+- descriptor: "test:logger:console:default:1.0"
+  level: "{{LOG_LEVEL}}"
+- include: ../common/counters.yml
+`)
+	writeConfigFile(t, filepath.Join(dir, "common", "counters.yml"), `
+- descriptor: "test:counters:log:default:1.0"
+`)
+	writeConfigFile(t, filepath.Join(dir, "conf.d", "b.yml"), `
+- descriptor: "test:persistence:memory:b:1.0"
+`)
+	writeConfigFile(t, filepath.Join(dir, "conf.d", "a.yml"), `
+- descriptor: "test:persistence:memory:a:1.0"
+`)
+
+	parameters := conf.NewConfigParamsFromTuples("LOG_LEVEL", "debug")
+	config, err := cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, parameters)
+	assert.Nil(t, err)
+	assert.Len(t, config, 5)
+
+	assert.Equal(t, "test:logger:console:default:1.0", config[0].Descriptor.String())
+	assert.Equal(t, "debug", config[0].Config.GetAsString("level"))
+	assert.Equal(t, "test:counters:log:default:1.0", config[1].Descriptor.String())
+	assert.Equal(t, "test:controller:default:default:1.0", config[2].Descriptor.String())
+	assert.Equal(t, "test:persistence:memory:a:1.0", config[3].Descriptor.String())
+	assert.Equal(t, "test:persistence:memory:b:1.0", config[4].Descriptor.String())
+}
+
+func TestReadConfigWithIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.yml")
+	writeConfigFile(t, path, `
+- include: ./a.yml
+`)
+	writeConfigFile(t, filepath.Join(dir, "a.yml"), `
+- include: ./b.yml
+`)
+	writeConfigFile(t, filepath.Join(dir, "b.yml"), `
+- include: ./config.yml
+`)
+
+	_, err := cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "config.yml -> "+filepath.Join(dir, "a.yml")+" -> "+filepath.Join(dir, "b.yml"))
+
+	writeConfigFile(t, filepath.Join(dir, "b.yml"), `
+- include: ./missing.yml
+`)
+
+	_, err = cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "b.yml")+" -> "+filepath.Join(dir, "missing.yml"))
+}
+
+func TestReadConfigWithStrictParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeConfigFile(t, path, `
+- descriptor: "test:persistence:mongodb:default:1.0"
+  connection:
+    host: {{DB_HOST|localhost}}
+    port: {{ DB_PORT | 27017 }}
+    database: {{DB_NAME}}
+{{#DB_USER}}
+  credential:
+    username: {{DB_USER}}
+    password: {{DB_PASSWORD}}
+{{/DB_USER}}
+`)
+
+	config, err := cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path,
+		conf.NewConfigParamsFromTuples("DB_PORT", "27018"))
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", config[0].Config.GetAsString("connection.host"))
+	assert.Equal(t, "27018", config[0].Config.GetAsString("connection.port"))
+	assert.Equal(t, "", config[0].Config.GetAsString("connection.database"))
+
+	strict := &cconf.ReadOptions{Strict: true}
+
+	_, err = cconf.ContainerConfigReader.ReadFromFileWithOptions(context.Background(), "123", path, nil, strict)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "1 unresolved parameter(s): DB_NAME at "+path+":6")
+
+	_, err = cconf.ContainerConfigReader.ReadFromFileWithOptions(context.Background(), "123", path,
+		conf.NewConfigParamsFromTuples("DB_USER", "admin"), strict)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "2 unresolved parameter(s): DB_NAME at "+path+":6, DB_PASSWORD at "+path+":10")
+
+	config, err = cconf.ContainerConfigReader.ReadFromFileWithOptions(context.Background(), "123", path,
+		conf.NewConfigParamsFromTuples("DB_NAME", "test", "DB_USER", "admin", "DB_PASSWORD", "pass"), strict)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", config[0].Config.GetAsString("connection.database"))
+	assert.Equal(t, "admin", config[0].Config.GetAsString("credential.username"))
+
+	// Strict mode of a single read does not apply to other reads
+	_, err = cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, nil)
+	assert.Nil(t, err)
+
+	cconf.ContainerConfigReader.SetStrict(true)
+	defer cconf.ContainerConfigReader.SetStrict(false)
+
+	_, err = cconf.ContainerConfigReader.ReadFromFile(context.Background(), "123", path, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "1 unresolved parameter(s): DB_NAME at "+path+":6")
+}
+
+func TestReadConfigWithStrictIncludes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	writeConfigFile(t, path, `
+- descriptor: "test:logger:console:default:1.0"
+  level: {{LOG_LEVEL}}
+- include: ./persistence.yml
+`)
+	includePath := filepath.Join(dir, "persistence.yml")
+	writeConfigFile(t, includePath, `
+- descriptor: "test:persistence:mongodb:default:1.0"
+  connection:
+    host: {{DB_HOST}}
+`)
+	overlayPath := filepath.Join(dir, "config.dev.yml")
+	writeConfigFile(t, overlayPath, `
+- descriptor: "test:logger:console:default:1.0"
+  source: {{LOG_SOURCE}}
+`)
+
+	// Placeholders without values in all files are reported in one error
+	_, err := cconf.ContainerConfigReader.ReadFromFileWithOptions(context.Background(), "123", path, nil,
+		&cconf.ReadOptions{Profiles: []string{"dev"}, Strict: true})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "3 unresolved parameter(s): LOG_LEVEL at "+path+":3, "+
+		"DB_HOST at "+includePath+":4, LOG_SOURCE at "+overlayPath+":3")
+
+	_, err = cconf.ContainerConfigReader.ReadFromFileWithOptions(context.Background(), "123", path,
+		conf.NewConfigParamsFromTuples("LOG_LEVEL", "debug"), &cconf.ReadOptions{Strict: true})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "1 unresolved parameter(s): DB_HOST at "+includePath+":4")
+}
@@ -0,0 +1,66 @@
+package test_container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pip-services3-gox/pip-services3-container-gox/container"
+)
+
+func newTestCommandLine() *container.CommandLine {
+	commandLine := container.NewCommandLine()
+	commandLine.AddFlag(&container.CommandLineFlag{Name: "config", Aliases: []string{"c"}, ValueName: "path", Default: "./config.yml"})
+	commandLine.AddFlag(&container.CommandLineFlag{Name: "param", Aliases: []string{"p"}, ValueName: "name=value", Repeated: true})
+	commandLine.AddFlag(&container.CommandLineFlag{Name: "graph", ValueName: "format", OptionalValue: true, Values: []string{"dot", "mermaid"}})
+	commandLine.AddFlag(&container.CommandLineFlag{Name: "verbose", Aliases: []string{"v"}})
+	return commandLine
+}
+
+func TestParseCommandLine(t *testing.T) {
+	commandLine := newTestCommandLine()
+
+	assert.Nil(t, commandLine.Parse([]string{}))
+	assert.Equal(t, "./config.yml", commandLine.GetValue("config"))
+	assert.False(t, commandLine.IsSet("verbose"))
+
+	err := commandLine.Parse([]string{"app", "--config=./my.yml", "-p=a=1", "-p", "b=-2", "-pc=3",
+		"--graph", "mermaid", "-v", "--", "--other"})
+	assert.Nil(t, err)
+	assert.Equal(t, "./my.yml", commandLine.GetValue("c"))
+	assert.Equal(t, []string{"a=1", "b=-2", "c=3"}, commandLine.GetValues("param"))
+	assert.Equal(t, "mermaid", commandLine.GetValue("graph"))
+	assert.True(t, commandLine.IsSet("verbose"))
+	assert.Equal(t, []string{"app", "--other"}, commandLine.GetArgs())
+
+	// Optional value is not consumed when it is not allowed
+	assert.Nil(t, commandLine.Parse([]string{"--graph", "app"}))
+	assert.True(t, commandLine.IsSet("graph"))
+	assert.Equal(t, "", commandLine.GetValue("graph"))
+	assert.Equal(t, []string{"app"}, commandLine.GetArgs())
+
+	assert.NotNil(t, commandLine.Parse([]string{"--unknown"}))
+	assert.NotNil(t, commandLine.Parse([]string{"--config"}))
+	assert.NotNil(t, commandLine.Parse([]string{"--graph=svg"}))
+	assert.NotNil(t, commandLine.Parse([]string{"--verbose=maybe"}))
+	assert.NotNil(t, commandLine.Parse([]string{"---config"}))
+
+	usage := commandLine.Usage()
+	assert.Contains(t, usage, "-c, --config <path>")
+	assert.Contains(t, usage, "(default: ./config.yml)")
+	assert.Contains(t, usage, "--graph [dot|mermaid]")
+}
+
+func TestRunWithApplicationFlags(t *testing.T) {
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFlag(&container.CommandLineFlag{Name: "port", ValueName: "number", Default: "8080", Description: "HTTP port"})
+
+	result := c.RunWithResult(context.Background(), []string{"--unknown"})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Equal(t, container.TerminationInvalidArguments, result.Reason)
+
+	result = c.RunWithResult(context.Background(), []string{"--port=9090", "--help"})
+	assert.Equal(t, container.TerminationHelp, result.Reason)
+	assert.Equal(t, "9090", c.CommandLine().GetValue("port"))
+}
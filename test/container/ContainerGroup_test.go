@@ -0,0 +1,106 @@
+package test_container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	"github.com/pip-services3-gox/pip-services3-container-gox/container"
+)
+
+func TestContainerGroupExports(t *testing.T) {
+	member1 := newTestContainer(
+		"a.descriptor", "test:component:default:a:1.0",
+		"b.descriptor", "test:component:default:b:1.0",
+	)
+	member2 := newTestContainer(
+		"c.descriptor", "test:component:default:c:1.0",
+	)
+
+	group := container.NewContainerGroup("group", container.GroupFailAll)
+	group.AddMember(member1, refer.NewDescriptor("test", "component", "default", "a", "1.0"))
+	group.AddMember(member2)
+
+	err := group.Open(context.Background(), "123")
+	assert.Nil(t, err)
+
+	// Only exported components are shared
+	assert.Same(t, getTestComponent(member1, "default", "a"), getTestComponent(member2, "default", "a"))
+	assert.Nil(t, getTestComponent(member2, "default", "b"))
+	assert.Nil(t, getTestComponent(member1, "default", "c"))
+	assert.True(t, group.CheckHealth(context.Background(), "123").IsHealthy())
+
+	err = group.Close(context.Background(), "123")
+	assert.Nil(t, err)
+	assert.False(t, member1.IsOpen())
+	assert.False(t, member2.IsOpen())
+}
+
+func TestContainerGroupFailures(t *testing.T) {
+	newGroup := func(failureMode container.GroupFailureMode) (*container.ContainerGroup, *container.Container) {
+		member := newTestContainer(
+			"a.descriptor", "test:component:default:a:1.0",
+		)
+		group := container.NewContainerGroup("group", failureMode)
+		group.AddMember(member)
+		group.AddMember(newTestContainer(
+			"b.descriptor", "test:component:failing:b:1.0",
+		))
+		return group, member
+	}
+
+	// Failure of one member fails the entire group
+	group, member := newGroup(container.GroupFailAll)
+	err := group.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.False(t, member.IsOpen())
+
+	// Failed member is isolated
+	group, member = newGroup(container.GroupIsolate)
+	err = group.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	defer group.Close(context.Background(), "123")
+	assert.True(t, member.IsOpen())
+
+	report := group.CheckHealth(context.Background(), "123")
+	assert.False(t, report.IsHealthy())
+	assert.Equal(t, "test/test:component:default:a:1.0", report.Components[0].Locator)
+	assert.Equal(t, container.HealthStatusHealthy, report.Components[0].Status)
+	assert.Equal(t, container.HealthStatusUnhealthy, report.Components[1].Status)
+}
+
+func TestContainerGroupUnexportComponents(t *testing.T) {
+	locator := refer.NewDescriptor("test", "component", "default", "a", "1.0")
+	member1 := newTestContainer(
+		"a.descriptor", "test:component:default:a:1.0",
+		"b.descriptor", "test:component:crashing:b:1.0",
+	)
+	member2 := newTestContainer(
+		"a.descriptor", "test:component:default:a:1.0",
+	)
+	member3 := newTestContainer(
+		"c.descriptor", "test:component:default:c:1.0",
+	)
+
+	group := container.NewContainerGroup("group", container.GroupIsolate)
+	group.AddMember(member1, locator)
+	group.AddMember(member2, locator)
+	group.AddMember(member3)
+
+	err := group.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	defer group.Close(context.Background(), "123")
+
+	// Member that crashed is isolated and only its exports are removed,
+	// while other members keep locating shared components
+	assert.Eventually(t, func() bool {
+		member3.References.GetOptional(locator)
+		return !member1.IsOpen()
+	}, time.Second, time.Millisecond)
+	components := member3.References.GetOptional(locator)
+	assert.Len(t, components, 1)
+	assert.Same(t, getTestComponent(member2, "default", "a"), components[0])
+}
@@ -0,0 +1,80 @@
+package test_container
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+	"github.com/pip-services3-gox/pip-services3-container-gox/container"
+)
+
+func TestEnvironmentOptions(t *testing.T) {
+	options := container.EnvironmentOptions{
+		Prefix:      "APP__",
+		StripPrefix: true,
+		Separator:   "__",
+		LowerCase:   true,
+	}
+
+	name, ok := options.GetParameterName("APP__LOGGER__LEVEL")
+	assert.True(t, ok)
+	assert.Equal(t, "logger.level", name)
+
+	_, ok = options.GetParameterName("PATH")
+	assert.False(t, ok)
+
+	path := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(path, []byte("# Comment\nexport APP__DB__HOST=\"db=local\"\nAPP__DB__PORT=5432\nOTHER=1\n"), 0644)
+	assert.Nil(t, err)
+
+	parameters, err := options.ReadEnvFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "db=local", parameters.GetAsString("db.host"))
+	assert.Equal(t, "5432", parameters.GetAsString("db.port"))
+	assert.Len(t, parameters.Keys(), 2)
+}
+
+func TestParameterPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	err := os.WriteFile(path, []byte(`
+- descriptor: "test:component:default:a:1.0"
+  cli: "{{A}}"
+  env: "{{B}}"
+  file: "{{C}}"
+  default: "{{D}}"
+`), 0644)
+	assert.Nil(t, err)
+	envPath := filepath.Join(dir, ".env")
+	err = os.WriteFile(envPath, []byte("TEST_A=file\nTEST_B=file\nTEST_C=file\n"), 0644)
+	assert.Nil(t, err)
+
+	t.Setenv("TEST_A", "env")
+	t.Setenv("TEST_B", "env")
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFactory(newTestFactory())
+	c.SetEnvironmentOptions(container.EnvironmentOptions{Prefix: "TEST_", StripPrefix: true})
+	c.SetDefaultParameters(cconfig.NewConfigParamsFromTuples("A", "default", "B", "default", "C", "default", "D", "default"))
+
+	result := c.RunWithResult(context.Background(),
+		[]string{"-c", path, "-p", "A=cli", "--env-file", envPath, "--validate"})
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+
+	buffer := &bytes.Buffer{}
+	err = c.WriteConfig(context.Background(), "123", buffer, config.ConfigFormatYaml)
+	assert.Nil(t, err)
+	output := buffer.String()
+	assert.Contains(t, output, "cli: cli")
+	assert.Contains(t, output, "env: env")
+	assert.Contains(t, output, "file: file")
+	assert.Contains(t, output, "default: default")
+	assert.Contains(t, output, "source: env_file")
+	assert.NotContains(t, output, "PATH")
+}
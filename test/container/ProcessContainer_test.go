@@ -0,0 +1,398 @@
+package test_container
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/run"
+	"github.com/pip-services3-gox/pip-services3-components-gox/build"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+	"github.com/pip-services3-gox/pip-services3-container-gox/container"
+)
+
+func TestRunWithMissingConfig(t *testing.T) {
+	c := container.NewProcessContainer("test", "Test container")
+
+	result := c.RunWithResult(context.Background(), []string{"-c", "./missing.yml"})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Equal(t, container.TerminationStartFailed, result.Reason)
+	assert.NotNil(t, result.Err)
+}
+
+func TestRunCancelledByContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte("---\n- descriptor: \"test:component:default:a:1.0\"\n"), 0644)
+	assert.Nil(t, err)
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFactory(newTestFactory())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan *container.RunResult, 1)
+	go func() {
+		results <- c.RunWithResult(ctx, []string{"-c", path})
+	}()
+
+	assert.Eventually(t, c.IsOpen, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case result := <-results:
+		assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+		assert.Equal(t, container.TerminationCancelled, result.Reason)
+		assert.Nil(t, result.Err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Container was not stopped")
+	}
+	assert.Equal(t, container.ContainerStateClosed, c.State())
+}
+
+type gatedComponent struct {
+	opening chan struct{}
+	release chan struct{}
+}
+
+func (c *gatedComponent) IsOpen() bool {
+	return true
+}
+
+func (c *gatedComponent) Open(ctx context.Context, correlationId string) error {
+	close(c.opening)
+	<-c.release
+	return nil
+}
+
+func (c *gatedComponent) Close(ctx context.Context, correlationId string) error {
+	return nil
+}
+
+func TestRunStoppedBeforeGroupOpened(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte("---\n- descriptor: \"test:gated:default:default:1.0\"\n"), 0644)
+	assert.Nil(t, err)
+
+	component := &gatedComponent{opening: make(chan struct{}), release: make(chan struct{})}
+	factory := build.NewFactory()
+	factory.Register(refer.NewDescriptor("test", "gated", "*", "*", "1.0"), func(locator any) any {
+		return component
+	})
+
+	member := newTestContainer(
+		"a.descriptor", "test:component:default:a:1.0",
+	)
+	group := container.NewContainerGroup("group", container.GroupFailAll)
+	group.AddMember(member)
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFactory(factory)
+	c.SetGroup(group)
+
+	results := make(chan *container.RunResult, 1)
+	go func() {
+		results <- c.RunWithResult(context.Background(), []string{"-c", path})
+	}()
+
+	// Stop after components of the process container started opening, but before the group
+	<-component.opening
+	err = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	assert.Nil(t, err)
+	time.Sleep(20 * time.Millisecond)
+	close(component.release)
+
+	result := waitRunResult(t, results)
+	assert.Equal(t, container.TerminationSignal, result.Reason)
+	assert.Equal(t, container.ContainerStateClosed, c.State())
+	assert.False(t, group.IsOpen())
+	assert.False(t, member.IsOpen())
+}
+
+func TestRunValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte(`
+- descriptor: "test:component:default:a:1.0"
+- descriptor: "test:unknown:default:a:1.0"
+- type: "MyComponent,mypackage"
+- descriptor: "test:component:default:b:1.0"
+  enabled: "ENV =="
+`), 0644)
+	assert.Nil(t, err)
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFactory(newTestFactory())
+
+	errs := c.Validate(context.Background(), "123")
+	assert.Len(t, errs, 0)
+
+	result := c.RunWithResult(context.Background(), []string{"-c", path, "--validate"})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Equal(t, container.TerminationValidate, result.Reason)
+	assert.NotNil(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "Found 3 configuration errors")
+	assert.Equal(t, container.ContainerStateCreated, c.State())
+
+	err = os.WriteFile(path, []byte(`
+- descriptor: "test:component:default:a:1.0"
+- name: missing
+- name: another
+`), 0644)
+	assert.Nil(t, err)
+
+	result = c.RunWithResult(context.Background(), []string{"-c", path, "--dry-run"})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Contains(t, result.Err.Error(), "Found 2 configuration errors")
+
+	err = os.WriteFile(path, []byte(`
+- descriptor: "test:component:default:a:1.0"
+`), 0644)
+	assert.Nil(t, err)
+
+	result = c.RunWithResult(context.Background(), []string{"-c", path, "--validate"})
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+	assert.Nil(t, result.Err)
+	assert.False(t, c.IsOpen())
+}
+
+func TestRunValidateStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte(`
+- descriptor: "test:component:default:a:1.0"
+  database: "{{DB_NAME}}"
+`), 0644)
+	assert.Nil(t, err)
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFactory(newTestFactory())
+
+	result := c.RunWithResult(context.Background(), []string{"validate", "-c", path, "--strict"})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Contains(t, result.Err.Error(), "DB_NAME at "+path+":3")
+
+	// Strict mode of one container does not change other readers
+	assert.False(t, config.ContainerConfigReader.IsStrict())
+
+	c = container.NewProcessContainer("test", "Test container")
+	c.AddFactory(newTestFactory())
+
+	result = c.RunWithResult(context.Background(), []string{"validate", "-c", path})
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+}
+
+func TestRunCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte(`
+- descriptor: "test:component:default:a:1.0"
+- descriptor: "pip-services:logger:console:default:1.0"
+- descriptor: "test:unknown:default:a:1.0"
+  enabled: "ENV == prod"
+`), 0644)
+	assert.Nil(t, err)
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddNamedFactory("test", newTestFactory())
+	c.SetVersion("1.2.0")
+
+	var commandArgs []string
+	c.AddCommand(&container.ContainerCommand{
+		Name:        "migrate",
+		Description: "migrate the database",
+		Action: func(ctx context.Context, correlationId string, c *container.ProcessContainer, args []string) error {
+			commandArgs = args
+			assert.False(t, c.IsOpen())
+			descriptions, err := c.Describe(ctx, correlationId)
+			assert.Nil(t, err)
+			assert.Len(t, descriptions, 3)
+			return nil
+		},
+	})
+
+	result := c.RunWithResult(context.Background(), []string{"migrate", "-c", path, "up"})
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+	assert.Equal(t, container.TerminationCommand, result.Reason)
+	assert.Equal(t, []string{"up"}, commandArgs)
+
+	descriptions, err := c.Describe(context.Background(), "123")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", descriptions[0].Factory)
+	assert.Equal(t, container.DefaultFactoryName, descriptions[1].Factory)
+	assert.Equal(t, "", descriptions[2].Factory)
+	assert.False(t, descriptions[2].Enabled)
+
+	result = c.RunWithResult(context.Background(), []string{"describe", "-c", path})
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+	assert.Equal(t, container.TerminationCommand, result.Reason)
+
+	result = c.RunWithResult(context.Background(), []string{"version"})
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+
+	result = c.RunWithResult(context.Background(), []string{"validate", "-c", path})
+	assert.Equal(t, container.TerminationValidate, result.Reason)
+
+	result = c.RunWithResult(context.Background(), []string{"print-config", "xml", "-c", path})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Equal(t, container.TerminationPrintConfig, result.Reason)
+
+	result = c.RunWithResult(context.Background(), []string{"unknown"})
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Equal(t, container.TerminationInvalidArguments, result.Reason)
+	assert.False(t, c.IsOpen())
+}
+
+type stoppingComponent struct {
+	lock     sync.Mutex
+	ctx      context.Context
+	closing  chan struct{}
+	release  chan struct{}
+	closedAt time.Time
+}
+
+func newStoppingComponent(release chan struct{}) *stoppingComponent {
+	return &stoppingComponent{closing: make(chan struct{}), release: release}
+}
+
+func (c *stoppingComponent) IsOpen() bool {
+	return true
+}
+
+func (c *stoppingComponent) Open(ctx context.Context, correlationId string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ctx = ctx
+	return nil
+}
+
+func (c *stoppingComponent) Close(ctx context.Context, correlationId string) error {
+	close(c.closing)
+	if c.release != nil {
+		<-c.release
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closedAt = time.Now()
+	return nil
+}
+
+func (c *stoppingComponent) context() context.Context {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ctx
+}
+
+func runStoppingContainer(t *testing.T, ctx context.Context, component *stoppingComponent,
+	gracePeriod time.Duration, preStopDelay time.Duration) (*container.ProcessContainer, chan *container.RunResult) {
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte("---\n- descriptor: \"test:stopping:default:default:1.0\"\n"), 0644)
+	assert.Nil(t, err)
+
+	factory := build.NewFactory()
+	factory.Register(refer.NewDescriptor("test", "stopping", "*", "*", "1.0"), func(locator any) any {
+		return component
+	})
+
+	c := container.NewProcessContainer("test", "Test container")
+	c.AddFactory(factory)
+	c.SetShutdownOptions(gracePeriod, preStopDelay)
+
+	results := make(chan *container.RunResult, 1)
+	go func() {
+		results <- c.RunWithResult(ctx, []string{"-c", path})
+	}()
+	assert.Eventually(t, c.IsOpen, time.Second, time.Millisecond)
+	return c, results
+}
+
+func waitRunResult(t *testing.T, results chan *container.RunResult) *container.RunResult {
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "Container was not stopped")
+		return nil
+	}
+}
+
+func TestShutdownWithPreStopDelay(t *testing.T) {
+	component := newStoppingComponent(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, results := runStoppingContainer(t, ctx, component, 0, 100*time.Millisecond)
+
+	start := time.Now()
+	cancel()
+
+	result := waitRunResult(t, results)
+	assert.Equal(t, container.ExitCodeSuccess, result.ExitCode)
+	assert.Equal(t, container.TerminationCancelled, result.Reason)
+	assert.GreaterOrEqual(t, component.closedAt.Sub(start), 100*time.Millisecond)
+}
+
+func TestShutdownGracePeriodExceeded(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	component := newStoppingComponent(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, results := runStoppingContainer(t, ctx, component, 50*time.Millisecond, 0)
+
+	cancel()
+
+	result := waitRunResult(t, results)
+	assert.Equal(t, container.ExitCodeShutdownTimeout, result.ExitCode)
+	assert.Equal(t, container.TerminationCancelled, result.Reason)
+	assert.Contains(t, result.Err.Error(), "grace period")
+}
+
+func TestShutdownForcedBySecondSignal(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	component := newStoppingComponent(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, results := runStoppingContainer(t, ctx, component, 0, 0)
+
+	cancel()
+	<-component.closing
+	err := syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	assert.Nil(t, err)
+
+	result := waitRunResult(t, results)
+	assert.Equal(t, container.ExitCodeForced, result.ExitCode)
+	assert.Equal(t, container.TerminationCancelled, result.Reason)
+}
+
+func TestShutdownAfterFailure(t *testing.T) {
+	failure := errors.New("connection lost")
+
+	component := newStoppingComponent(nil)
+	_, results := runStoppingContainer(t, context.Background(), component, 0, 0)
+	assert.Eventually(t, func() bool {
+		return run.SendShutdownSignalWithErr(component.context(), failure)
+	}, time.Second, time.Millisecond)
+
+	result := waitRunResult(t, results)
+	assert.Equal(t, container.ExitCodeFailure, result.ExitCode)
+	assert.Equal(t, container.TerminationError, result.Reason)
+	assert.Equal(t, failure, result.Err)
+
+	// Exit code of the shutdown that exceeded its grace period is kept
+	release := make(chan struct{})
+	defer close(release)
+	component = newStoppingComponent(release)
+	_, results = runStoppingContainer(t, context.Background(), component, 50*time.Millisecond, 0)
+	assert.Eventually(t, func() bool {
+		return run.SendShutdownSignalWithErr(component.context(), failure)
+	}, time.Second, time.Millisecond)
+
+	result = waitRunResult(t, results)
+	assert.Equal(t, container.ExitCodeShutdownTimeout, result.ExitCode)
+	assert.Equal(t, container.TerminationError, result.Reason)
+	assert.Contains(t, result.Err.Error(), "grace period")
+	assert.Equal(t, "connection lost", result.Err.(*cerr.ApplicationError).Cause)
+}
@@ -0,0 +1,266 @@
+package test_refer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cconfig "github.com/pip-services3-gox/pip-services3-commons-gox/config"
+	cerr "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/run"
+	"github.com/pip-services3-gox/pip-services3-components-gox/build"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+	crefer "github.com/pip-services3-gox/pip-services3-container-gox/refer"
+)
+
+type reloadComponent struct {
+	*runComponent
+	message    string
+	dependency any
+}
+
+func (c *reloadComponent) Configure(ctx context.Context, config *cconfig.ConfigParams) {
+	c.name = config.GetAsString("descriptor")
+	c.message = config.GetAsString("message")
+}
+
+func (c *reloadComponent) SetReferences(ctx context.Context, references refer.IReferences) {
+	c.dependency = references.GetOneOptional(refer.NewDescriptor("test", "component", "default", "a", "1.0"))
+}
+
+func newReloadReferences(recorder *runRecorder) *crefer.ContainerReferences {
+	factory := build.NewFactory()
+	factory.Register(
+		refer.NewDescriptor("test", "component", "*", "*", "1.0"),
+		func(locator any) any {
+			return &reloadComponent{runComponent: newRunComponent("", recorder)}
+		},
+	)
+
+	refs := crefer.NewContainerReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "factory", "default", "default", "1.0"), factory)
+	return refs
+}
+
+func readTestConfig(tuples ...any) config.ContainerConfig {
+	result, _ := config.ReadContainerConfigFromConfig(cconfig.NewConfigParamsFromTuples(tuples...))
+	return result
+}
+
+func TestReloadFromConfig(t *testing.T) {
+	recorder := &runRecorder{}
+	refs := newReloadReferences(recorder)
+
+	err := refs.PutFromConfig(context.Background(), readTestConfig(
+		"a.descriptor", "test:component:default:a:1.0",
+		"b.descriptor", "test:component:default:b:1.0",
+		"b.message", "hello",
+		"c.descriptor", "test:component:default:c:1.0",
+		"c.message", "hello",
+		"c.reconfigurable", true,
+	))
+	assert.Nil(t, err)
+
+	err = refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	componentC := refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "c", "1.0")).(*reloadComponent)
+	recorder.events = nil
+
+	err = refs.ReloadFromConfig(context.Background(), "123", readTestConfig(
+		"b.descriptor", "test:component:default:b:1.0",
+		"b.message", "bye",
+		"c.descriptor", "test:component:default:c:1.0",
+		"c.message", "bye",
+		"c.reconfigurable", true,
+		"d.descriptor", "test:component:default:d:1.0",
+	))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"close test:component:default:a:1.0",
+		"close test:component:default:b:1.0",
+		"open test:component:default:b:1.0",
+		"open test:component:default:d:1.0",
+	}, recorder.events)
+
+	assert.Nil(t, refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "a", "1.0")))
+	componentB := refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "b", "1.0")).(*reloadComponent)
+	assert.Equal(t, "bye", componentB.message)
+	assert.True(t, componentB.IsOpen())
+
+	// Reconfigurable component is updated in place
+	assert.Same(t, componentC, refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "c", "1.0")))
+	assert.Equal(t, "bye", componentC.message)
+	assert.True(t, componentC.IsOpen())
+
+	err = refs.Close(context.Background(), "123")
+	assert.Nil(t, err)
+}
+
+func TestReloadDependentComponents(t *testing.T) {
+	recorder := &runRecorder{}
+	refs := newReloadReferences(recorder)
+
+	err := refs.PutFromConfig(context.Background(), readTestConfig(
+		"a.descriptor", "test:component:default:a:1.0",
+		"a.message", "hello",
+		"b.descriptor", "test:component:default:b:1.0",
+		"b.dependencies.a", "test:component:default:a:1.0",
+	))
+	assert.Nil(t, err)
+
+	err = refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	locatorA := refer.NewDescriptor("test", "component", "default", "a", "1.0")
+	componentB := refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "b", "1.0")).(*reloadComponent)
+	assert.Same(t, refs.GetOneOptional(locatorA), componentB.dependency)
+
+	// The dependent gets the recreated component
+	err = refs.ReloadFromConfig(context.Background(), "123", readTestConfig(
+		"a.descriptor", "test:component:default:a:1.0",
+		"a.message", "bye",
+		"b.descriptor", "test:component:default:b:1.0",
+		"b.dependencies.a", "test:component:default:a:1.0",
+	))
+	assert.Nil(t, err)
+
+	componentA := refs.GetOneOptional(locatorA).(*reloadComponent)
+	assert.Equal(t, "bye", componentA.message)
+	assert.Same(t, componentA, componentB.dependency)
+
+	// The dependent does not keep the removed component
+	err = refs.ReloadFromConfig(context.Background(), "123", readTestConfig(
+		"b.descriptor", "test:component:default:b:1.0",
+		"b.dependencies.a", "test:component:default:a:1.0",
+	))
+	assert.Nil(t, err)
+	assert.Nil(t, componentB.dependency)
+}
+
+func TestReloadSupervisedComponents(t *testing.T) {
+	recorder := &runRecorder{}
+	refs := newReloadReferences(recorder)
+
+	err := refs.PutFromConfig(context.Background(), readTestConfig(
+		"a.descriptor", "test:component:default:a:1.0",
+		"a.restart.policy", "on_failure",
+		"a.restart.max_restarts", 0,
+		"c.descriptor", "test:component:default:c:1.0",
+		"c.message", "hello",
+		"c.reconfigurable", true,
+		"c.restart.policy", "on_failure",
+		"c.restart.max_restarts", 0,
+	))
+	assert.Nil(t, err)
+
+	shutdown := make(run.ContextShutdownWithErrorChan, 1)
+	ctx, _ := run.AddErrShutdownChanToContext(context.Background(), shutdown)
+
+	err = refs.Open(ctx, "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	componentA := refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "a", "1.0")).(*reloadComponent)
+	componentC := refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "c", "1.0")).(*reloadComponent)
+
+	err = refs.ReloadFromConfig(context.Background(), "123", readTestConfig(
+		"c.descriptor", "test:component:default:c:1.0",
+		"c.message", "bye",
+		"c.reconfigurable", true,
+		"c.restart.policy", "on_failure",
+		"c.restart.max_restarts", 1,
+		"c.restart.backoff", 10,
+	))
+	assert.Nil(t, err)
+	recorder.lock.Lock()
+	recorder.events = nil
+	recorder.lock.Unlock()
+
+	// Removed component is not supervised anymore
+	failure := errors.New("connection lost")
+	run.SendShutdownSignalWithErr(componentA.ctx, failure)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, recorder.count())
+	assert.Len(t, shutdown, 0)
+
+	// Component reconfigured in place is restarted by the updated policy
+	assert.True(t, run.SendShutdownSignalWithErr(componentC.ctx, failure))
+	assert.Eventually(t, func() bool { return recorder.count() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{
+		"close test:component:default:c:1.0",
+		"open test:component:default:c:1.0",
+	}, recorder.events)
+	assert.Len(t, shutdown, 0)
+}
+
+func TestRestartDuringReload(t *testing.T) {
+	recorder := &runRecorder{}
+	refs := newReloadReferences(recorder)
+	readConfig := func(message string) config.ContainerConfig {
+		return readTestConfig(
+			"a.descriptor", "test:component:default:a:1.0",
+			"a.restart.policy", "on_failure",
+			"a.restart.max_restarts", 1000,
+			"a.restart.backoff", 1,
+			"a.restart.max_backoff", 1,
+			"b.descriptor", "test:component:default:b:1.0",
+			"b.message", message,
+		)
+	}
+
+	err := refs.PutFromConfig(context.Background(), readConfig("hello"))
+	assert.Nil(t, err)
+
+	shutdown := make(run.ContextShutdownWithErrorChan, 1)
+	ctx, _ := run.AddErrShutdownChanToContext(context.Background(), shutdown)
+
+	err = refs.Open(ctx, "123")
+	assert.Nil(t, err)
+
+	componentA := refs.GetOneOptional(refer.NewDescriptor("test", "component", "default", "a", "1.0")).(*reloadComponent)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		failure := errors.New("connection lost")
+		for index := 0; index < 100; index++ {
+			run.SendShutdownSignalWithErr(componentA.context(), failure)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// Components recreated by reload are opened while the failed component is restarted
+	for index := 0; index < 100; index++ {
+		err = refs.ReloadFromConfig(context.Background(), "123", readConfig(fmt.Sprint(index)))
+		assert.Nil(t, err)
+	}
+	<-done
+
+	err = refs.Close(context.Background(), "123")
+	assert.Nil(t, err)
+	assert.Len(t, shutdown, 0)
+}
+
+func TestCreateErrorWithoutSecrets(t *testing.T) {
+	refs := crefer.NewContainerReferences()
+
+	err := refs.PutFromConfig(context.Background(), readTestConfig(
+		"a.type", "UnknownComponent,unknown/module",
+		"a.connection.password", "pass123",
+	))
+	assert.NotNil(t, err)
+
+	// Error details are serialized when errors are passed between services
+	data, jsonErr := json.Marshal(err.(*cerr.ApplicationError).Details)
+	assert.Nil(t, jsonErr)
+	assert.Contains(t, string(data), "UnknownComponent")
+	assert.NotContains(t, string(data), "pass123")
+}
@@ -0,0 +1,62 @@
+package test_refer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	crefer "github.com/pip-services3-gox/pip-services3-container-gox/refer"
+)
+
+type lookupComponent struct {
+	required []any
+	optional []any
+}
+
+func (c *lookupComponent) SetReferences(ctx context.Context, references refer.IReferences) {
+	for _, locator := range c.required {
+		_, _ = references.GetOneRequired(locator)
+	}
+	for _, locator := range c.optional {
+		references.GetOptional(locator)
+	}
+}
+
+func TestReferenceGraph(t *testing.T) {
+	ctx := context.Background()
+	refs := crefer.NewEmptyManagedReferences()
+	graph := crefer.NewReferenceGraph()
+	refs.Linker.Graph = graph
+
+	persistence := &lookupComponent{}
+	controller := &lookupComponent{
+		required: []any{refer.NewDescriptor("test", "persistence", "*", "*", "1.0")},
+		optional: []any{refer.NewDescriptor("test", "cache", "*", "*", "1.0")},
+	}
+	refs.Put(ctx, refer.NewDescriptor("test", "persistence", "memory", "default", "1.0"), persistence)
+	refs.Put(ctx, refer.NewDescriptor("test", "controller", "default", "default", "1.0"), controller)
+
+	err := refs.Open(ctx, "123")
+	assert.Nil(t, err)
+	defer refs.Close(ctx, "123")
+
+	lookups := graph.GetLookups()
+	assert.Len(t, lookups, 2)
+	assert.Equal(t, "test:controller:default:default:1.0", lookups[0].Consumer.(*refer.Descriptor).String())
+	assert.True(t, lookups[0].Required)
+	assert.Len(t, lookups[0].Resolved, 1)
+	assert.False(t, lookups[1].Required)
+	assert.Len(t, lookups[1].Resolved, 0)
+
+	dot := graph.ToDot()
+	assert.Contains(t, dot, "n0 -> n1 [label=\"required\"];")
+	assert.Contains(t, dot, "n0 -> n2 [label=\"optional\", style=dashed, color=red, fontcolor=red];")
+	assert.Contains(t, dot, "n2 [label=\"test:cache:*:*:1.0\", style=dashed, color=red, fontcolor=red];")
+
+	mermaid := graph.ToMermaid()
+	assert.Contains(t, mermaid, "n0 -->|required| n1")
+	assert.Contains(t, mermaid, "n0 -.->|optional| n2")
+	assert.Contains(t, mermaid, "class n2 missing")
+}
@@ -0,0 +1,596 @@
+package test_refer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/refer"
+	"github.com/pip-services3-gox/pip-services3-commons-gox/run"
+	"github.com/pip-services3-gox/pip-services3-container-gox/config"
+	crefer "github.com/pip-services3-gox/pip-services3-container-gox/refer"
+)
+
+type runRecorder struct {
+	lock       sync.Mutex
+	events     []string
+	running    int
+	maxRunning int
+}
+
+func (c *runRecorder) record(event string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.events = append(c.events, event)
+}
+
+func (c *runRecorder) enter() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.running++
+	if c.running > c.maxRunning {
+		c.maxRunning = c.running
+	}
+}
+
+func (c *runRecorder) leave() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.running--
+}
+
+type runComponent struct {
+	name     string
+	recorder *runRecorder
+	opened   bool
+	delay    time.Duration
+	// ignoreCtx keeps opening after the context is cancelled
+	ignoreCtx bool
+	openErr   error
+	closeErr  error
+	hang      chan struct{}
+	ctx       context.Context
+}
+
+func newRunComponent(name string, recorder *runRecorder) *runComponent {
+	return &runComponent{name: name, recorder: recorder}
+}
+
+func (c *runComponent) IsOpen() bool {
+	return c.opened
+}
+
+func (c *runComponent) Open(ctx context.Context, correlationId string) error {
+	c.recorder.enter()
+	defer c.recorder.leave()
+
+	if c.delay > 0 && c.ignoreCtx {
+		time.Sleep(c.delay)
+	} else if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if c.openErr != nil {
+		return c.openErr
+	}
+
+	c.opened = true
+	c.recorder.lock.Lock()
+	c.ctx = ctx
+	c.recorder.lock.Unlock()
+	c.recorder.record("open " + c.name)
+	return nil
+}
+
+// context gets the context the component was last opened with.
+func (c *runComponent) context() context.Context {
+	c.recorder.lock.Lock()
+	defer c.recorder.lock.Unlock()
+	return c.ctx
+}
+
+func (c *runComponent) Close(ctx context.Context, correlationId string) error {
+	if c.hang != nil {
+		// Ignore the context to simulate a stuck component
+		<-c.hang
+	}
+	c.opened = false
+	c.recorder.record("close " + c.name)
+	return c.closeErr
+}
+
+func TestOpenInDependencyOrder(t *testing.T) {
+	recorder := &runRecorder{}
+	controller := newRunComponent("controller", recorder)
+	persistence := newRunComponent("persistence", recorder)
+	connection := newRunComponent("connection", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "controller", "default", "default", "1.0"), controller)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "persistence", "default", "default", "1.0"), persistence)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+
+	refs.Runner.Dependencies.AddDependencies(controller, refer.NewDescriptor("test", "persistence", "*", "*", "1.0"))
+	refs.Runner.Dependencies.AddDependencies(persistence, refer.NewDescriptor("test", "connection", "*", "*", "1.0"))
+
+	err := refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+
+	err = refs.Close(context.Background(), "123")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"open connection", "open persistence", "open controller",
+		"close controller", "close persistence", "close connection",
+	}, recorder.events)
+}
+
+func TestOpenWithCircularDependency(t *testing.T) {
+	recorder := &runRecorder{}
+	component1 := newRunComponent("component1", recorder)
+	component2 := newRunComponent("component2", recorder)
+	component3 := newRunComponent("component3", recorder)
+
+	descriptor1 := refer.NewDescriptor("test", "component", "default", "component1", "1.0")
+	descriptor2 := refer.NewDescriptor("test", "component", "default", "component2", "1.0")
+	descriptor3 := refer.NewDescriptor("test", "component", "default", "component3", "1.0")
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), descriptor1, component1)
+	refs.Put(context.Background(), descriptor2, component2)
+	refs.Put(context.Background(), descriptor3, component3)
+
+	refs.Runner.Dependencies.AddDependencies(component1, descriptor2)
+	refs.Runner.Dependencies.AddDependencies(component2, descriptor3)
+	refs.Runner.Dependencies.AddDependencies(component3, descriptor1)
+
+	err := refs.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), descriptor1.String())
+	assert.Contains(t, err.Error(), descriptor2.String())
+	assert.Contains(t, err.Error(), descriptor3.String())
+	assert.Empty(t, recorder.events)
+}
+
+func TestOpenInParallel(t *testing.T) {
+	recorder := &runRecorder{}
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Runner.SetParallel(true, 2)
+
+	components := make([]*runComponent, 4)
+	for index := range components {
+		name := "component" + string(rune('1'+index))
+		components[index] = newRunComponent(name, recorder)
+		components[index].delay = 50 * time.Millisecond
+		refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", name, "1.0"), components[index])
+	}
+
+	err := refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, recorder.maxRunning)
+	for _, component := range components {
+		assert.True(t, component.IsOpen())
+	}
+
+	err = refs.Close(context.Background(), "123")
+	assert.Nil(t, err)
+	for _, component := range components {
+		assert.False(t, component.IsOpen())
+	}
+}
+
+func TestOpenInParallelKeepsContext(t *testing.T) {
+	recorder := &runRecorder{}
+	connection := newRunComponent("connection", recorder)
+	persistence := newRunComponent("persistence", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Runner.SetParallel(true, 0)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "persistence", "default", "default", "1.0"), persistence)
+	refs.Runner.Dependencies.AddDependencies(persistence, refer.NewDescriptor("test", "connection", "*", "*", "1.0"))
+
+	err := refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+
+	// Components may keep the context they were opened with
+	assert.Nil(t, connection.context().Err())
+	assert.Nil(t, persistence.context().Err())
+
+	err = refs.Close(context.Background(), "123")
+	assert.Nil(t, err)
+}
+
+func TestOpenInParallelWithFailure(t *testing.T) {
+	recorder := &runRecorder{}
+	connection := newRunComponent("connection", recorder)
+	persistence := newRunComponent("persistence", recorder)
+	persistence.openErr = errors.New("cannot open persistence")
+	controller := newRunComponent("controller", recorder)
+	slow := newRunComponent("slow", recorder)
+	slow.delay = 10 * time.Second
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Runner.SetParallel(true, 0)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "persistence", "default", "default", "1.0"), persistence)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "controller", "default", "default", "1.0"), controller)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "slow", "default", "default", "1.0"), slow)
+
+	refs.Runner.Dependencies.AddDependencies(persistence, refer.NewDescriptor("test", "connection", "*", "*", "1.0"))
+	refs.Runner.Dependencies.AddDependencies(controller, refer.NewDescriptor("test", "persistence", "*", "*", "1.0"))
+
+	start := time.Now()
+	err := refs.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	assert.False(t, connection.IsOpen())
+	assert.False(t, controller.IsOpen())
+	assert.False(t, slow.IsOpen())
+	assert.Contains(t, recorder.events, "close connection")
+	assert.NotContains(t, recorder.events, "open controller")
+}
+
+func TestOpenTimeout(t *testing.T) {
+	recorder := &runRecorder{}
+	hung := newRunComponent("hung", recorder)
+	hung.delay = 10 * time.Second
+	descriptor := refer.NewDescriptor("test", "component", "default", "hung", "1.0")
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), descriptor, hung)
+	refs.Runner.SetTimeouts(10*time.Second, 0)
+	refs.Runner.SetComponentTimeouts(hung, 50*time.Millisecond, 0)
+
+	start := time.Now()
+	err := refs.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), descriptor.String())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestOpenTimeoutContext(t *testing.T) {
+	recorder := &runRecorder{}
+	component := newRunComponent("component", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "default", "1.0"), component)
+	refs.Runner.SetTimeouts(50*time.Millisecond, 0)
+
+	err := refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	// The deadline is passed to the component, but the context of opened component is not cancelled
+	_, ok := component.context().Deadline()
+	assert.True(t, ok)
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, component.context().Err())
+}
+
+func TestCloseComponentOpenedAfterTimeout(t *testing.T) {
+	recorder := &runRecorder{}
+	late := newRunComponent("late", recorder)
+	late.delay = 100 * time.Millisecond
+	late.ignoreCtx = true
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "late", "1.0"), late)
+	refs.Runner.SetTimeouts(20*time.Millisecond, 0)
+
+	err := refs.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "failed to open")
+
+	assert.Eventually(t, func() bool { return recorder.count() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"open late", "close late"}, recorder.events)
+	assert.Equal(t, context.DeadlineExceeded, late.context().Err())
+}
+
+func TestCloseTimeout(t *testing.T) {
+	recorder := &runRecorder{}
+	stuck := newRunComponent("stuck", recorder)
+	stuck.hang = make(chan struct{})
+	defer close(stuck.hang)
+	other := newRunComponent("other", recorder)
+	descriptor := refer.NewDescriptor("test", "component", "default", "stuck", "1.0")
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "other", "1.0"), other)
+	refs.Put(context.Background(), descriptor, stuck)
+	refs.Runner.SetTimeouts(0, 50*time.Millisecond)
+
+	err := refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+
+	err = refs.Close(context.Background(), "123")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), descriptor.String())
+	assert.False(t, other.IsOpen())
+}
+
+func TestRollbackAfterOpenCancelled(t *testing.T) {
+	recorder := &runRecorder{}
+	component1 := newRunComponent("component1", recorder)
+	component1.hang = make(chan struct{})
+	component2 := newRunComponent("component2", recorder)
+	component2.delay = 10 * time.Second
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "component1", "1.0"), component1)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "component2", "1.0"), component2)
+	refs.Runner.SetComponentTimeouts(component1, 0, 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	time.AfterFunc(100*time.Millisecond, func() { close(component1.hang) })
+
+	err := refs.Open(ctx, "123")
+	assert.NotNil(t, err)
+
+	// Opened components are closed within their close timeout after the open was cancelled
+	openErr, ok := err.(*crefer.OpenError)
+	assert.True(t, ok)
+	assert.Len(t, openErr.CloseErrors, 0)
+	assert.False(t, component1.IsOpen())
+	assert.Contains(t, recorder.events, "close component1")
+}
+
+func TestRollbackOpenedComponents(t *testing.T) {
+	recorder := &runRecorder{}
+	component1 := newRunComponent("component1", recorder)
+	component2 := newRunComponent("component2", recorder)
+	component2.closeErr = errors.New("cannot close component2")
+	component3 := newRunComponent("component3", recorder)
+	component3.openErr = errors.New("cannot open component3")
+	component4 := newRunComponent("component4", recorder)
+
+	descriptor2 := refer.NewDescriptor("test", "component", "default", "component2", "1.0")
+	descriptor3 := refer.NewDescriptor("test", "component", "default", "component3", "1.0")
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "component1", "1.0"), component1)
+	refs.Put(context.Background(), descriptor2, component2)
+	refs.Put(context.Background(), descriptor3, component3)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "component", "default", "component4", "1.0"), component4)
+
+	err := refs.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+
+	openErr, ok := err.(*crefer.OpenError)
+	assert.True(t, ok)
+	assert.Equal(t, descriptor3, openErr.Cause.Locator)
+	assert.Equal(t, component3.openErr, openErr.Cause.Err)
+	assert.Len(t, openErr.CloseErrors, 1)
+	assert.Equal(t, descriptor2, openErr.CloseErrors[0].Locator)
+	assert.True(t, errors.Is(err, component3.openErr))
+
+	assert.Equal(t, []string{
+		"open component1", "open component2",
+		"close component2", "close component1",
+	}, recorder.events)
+
+	// Components were already rolled back
+	err = refs.Close(context.Background(), "123")
+	assert.Nil(t, err)
+	assert.Len(t, recorder.events, 4)
+	assert.False(t, run.Opener.IsOpen(refs.GetAll()))
+}
+
+func (c *runRecorder) count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.events)
+}
+
+func TestRestartFailedComponent(t *testing.T) {
+	recorder := &runRecorder{}
+	connection := newRunComponent("connection", recorder)
+	persistence := newRunComponent("persistence", recorder)
+	other := newRunComponent("other", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "persistence", "default", "default", "1.0"), persistence)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "other", "default", "default", "1.0"), other)
+	refs.Runner.Dependencies.AddDependencies(persistence, refer.NewDescriptor("test", "connection", "*", "*", "1.0"))
+
+	policy := config.NewRestartPolicy(config.RestartOnFailure)
+	policy.MaxRestarts = 1
+	policy.Backoff = 10 * time.Millisecond
+	refs.Runner.SetComponentRestartPolicy(connection, policy)
+
+	shutdown := make(run.ContextShutdownWithErrorChan, 1)
+	ctx, _ := run.AddErrShutdownChanToContext(context.Background(), shutdown)
+
+	err := refs.Open(ctx, "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	// The connection and the persistence that depends on it are restarted
+	failure := errors.New("connection lost")
+	assert.True(t, run.SendShutdownSignalWithErr(connection.ctx, failure))
+	assert.Eventually(t, func() bool { return recorder.count() == 7 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{
+		"open connection", "open persistence", "open other",
+		"close persistence", "close connection",
+		"open connection", "open persistence",
+	}, recorder.events)
+	assert.Len(t, shutdown, 0)
+
+	// The process is shut down when the restart budget is exhausted
+	assert.True(t, run.SendShutdownSignalWithErr(connection.ctx, failure))
+	select {
+	case err = <-shutdown:
+		assert.True(t, errors.Is(err, failure))
+	case <-time.After(time.Second):
+		assert.Fail(t, "Shutdown signal was not sent")
+	}
+}
+
+func TestRestartCounterReset(t *testing.T) {
+	recorder := &runRecorder{}
+	connection := newRunComponent("connection", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+
+	policy := config.NewRestartPolicy(config.RestartOnFailure)
+	policy.MaxRestarts = 1
+	policy.Backoff = 10 * time.Millisecond
+	policy.ResetAfter = 50 * time.Millisecond
+	refs.Runner.SetComponentRestartPolicy(connection, policy)
+
+	shutdown := make(run.ContextShutdownWithErrorChan, 1)
+	ctx, _ := run.AddErrShutdownChanToContext(context.Background(), shutdown)
+
+	err := refs.Open(ctx, "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	// Failures after a stable period are restarted again
+	failure := errors.New("connection lost")
+	for index := 1; index <= 3; index++ {
+		time.Sleep(100 * time.Millisecond)
+		assert.True(t, run.SendShutdownSignalWithErr(connection.context(), failure))
+		assert.Eventually(t, func() bool { return recorder.count() == 1+2*index }, time.Second, time.Millisecond)
+	}
+	assert.Len(t, shutdown, 0)
+}
+
+func TestRestartBudgetExhaustedWithoutReceiver(t *testing.T) {
+	recorder := &runRecorder{}
+	connection := newRunComponent("connection", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+
+	policy := config.NewRestartPolicy(config.RestartOnFailure)
+	policy.MaxRestarts = 0
+	refs.Runner.SetComponentRestartPolicy(connection, policy)
+
+	// Nobody receives from the channel when the failure is sent
+	shutdown := make(run.ContextShutdownWithErrorChan)
+	ctx, _ := run.AddErrShutdownChanToContext(context.Background(), shutdown)
+
+	err := refs.Open(ctx, "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	failure := errors.New("connection lost")
+	assert.True(t, run.SendShutdownSignalWithErr(connection.context(), failure))
+	time.Sleep(50 * time.Millisecond)
+
+	select {
+	case err = <-shutdown:
+		assert.True(t, errors.Is(err, failure))
+	case <-time.After(time.Second):
+		assert.Fail(t, "Shutdown signal was lost")
+	}
+}
+
+func TestRemoveWhileRestarting(t *testing.T) {
+	recorder := &runRecorder{}
+	connection := newRunComponent("connection", recorder)
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Put(context.Background(), refer.NewDescriptor("test", "connection", "default", "default", "1.0"), connection)
+
+	policy := config.NewRestartPolicy(config.RestartOnFailure)
+	policy.MaxRestarts = 1000
+	policy.Backoff = time.Millisecond
+	policy.MaxBackoff = time.Millisecond
+	refs.Runner.SetComponentRestartPolicy(connection, policy)
+
+	locators := make([]any, 20)
+	for index := range locators {
+		component := newRunComponent("component", recorder)
+		locators[index] = refer.NewDescriptor("test", "component", "default", fmt.Sprintf("%d", index), "1.0")
+		refs.Put(context.Background(), locators[index], component)
+		refs.Runner.SetComponentTimeouts(component, time.Second, time.Second)
+	}
+
+	err := refs.Open(context.Background(), "123")
+	assert.Nil(t, err)
+	defer refs.Close(context.Background(), "123")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range locators {
+			run.SendShutdownSignalWithErr(connection.context(), errors.New("connection lost"))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// Removed components are closed and forgotten while the connection is restarted
+	for _, locator := range locators {
+		assert.NotNil(t, refs.Runner.Remove(context.Background(), locator))
+		assert.True(t, refs.Runner.IsOpen())
+		time.Sleep(time.Millisecond)
+	}
+	<-done
+}
+
+type eventRecorder struct {
+	lock   sync.Mutex
+	events []*crefer.LifecycleEvent
+}
+
+func (c *eventRecorder) OnLifecycleEvent(ctx context.Context, correlationId string, event *crefer.LifecycleEvent) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.events = append(c.events, event)
+}
+
+func (c *eventRecorder) types(locator any) []crefer.LifecycleEventType {
+	result := make([]crefer.LifecycleEventType, 0)
+	for _, event := range c.events {
+		if event.Locator == locator {
+			result = append(result, event.Type)
+		}
+	}
+	return result
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	recorder := &runRecorder{}
+	component1 := newRunComponent("component1", recorder)
+	component2 := newRunComponent("component2", recorder)
+	component2.openErr = errors.New("cannot open component2")
+	descriptor1 := refer.NewDescriptor("test", "component", "default", "component1", "1.0")
+	descriptor2 := refer.NewDescriptor("test", "component", "default", "component2", "1.0")
+
+	// Listener registered in code
+	registered := &eventRecorder{}
+	// Listener discovered among components
+	discovered := &eventRecorder{}
+
+	refs := crefer.NewEmptyManagedReferences()
+	refs.Events.AddListener(registered)
+	refs.Put(context.Background(), refer.NewDescriptor("test", "listener", "default", "default", "1.0"), discovered)
+	refs.Put(context.Background(), descriptor1, component1)
+	refs.Put(context.Background(), descriptor2, component2)
+
+	err := refs.Open(context.Background(), "123")
+	assert.NotNil(t, err)
+
+	for _, listener := range []*eventRecorder{registered, discovered} {
+		assert.Equal(t, []crefer.LifecycleEventType{crefer.ComponentOpened, crefer.ComponentClosed}, listener.types(descriptor1))
+		assert.Equal(t, []crefer.LifecycleEventType{crefer.ComponentFailed}, listener.types(descriptor2))
+	}
+
+	failed := registered.events[1]
+	assert.Equal(t, component2, failed.Component)
+	assert.Equal(t, component2.openErr, failed.Error)
+	assert.False(t, failed.Time.IsZero())
+}